@@ -38,12 +38,35 @@ func main() {
 		&cli.StringSliceFlag{
 			Name:    "include-tag",
 			Aliases: []string{"i"},
-			Usage:   "Tag to include (accept list - accepts all, if unset)",
+			Usage:   "Tag to include, including nested tags (accept list - accepts all, if unset)",
 		},
 		&cli.StringSliceFlag{
 			Name:    "exclude-tag",
 			Aliases: []string{"e"},
-			Usage:   "Tag to exclude (reject list - reject none, if unset)",
+			Usage:   "Tag to exclude, including nested tags (reject list - reject none, if unset)",
+		},
+		&cli.StringFlag{
+			Name:    "select",
+			Aliases: []string{"s"},
+			Usage:   "Selection `expression` of notes to convert, like 'tags has \"public\" and not (status == \"draft\")', in addition to included and excluded tags",
+		},
+		&cli.StringSliceFlag{
+			Name:  "ignore",
+			Usage: "Gitignore-style pattern of notes, files and directories to ignore, in addition to '.omhignore' files in the vault",
+		},
+		&cli.StringFlag{
+			Name:  "publish-mode",
+			Usage: "How the publish flag of notes is respected: ignore, required (only notes with 'publish: true') or respect (never notes with 'publish: false')",
+			Value: string(omh.PublishIgnore),
+		},
+		&cli.StringFlag{
+			Name:  "publish-key",
+			Usage: "Name of Front Matter attribute of the publish flag",
+			Value: omh.DefaultPublishKey,
+		},
+		&cli.BoolFlag{
+			Name:  "without-front-matter",
+			Usage: "Convert notes without Front Matter, dated by the last modification of the file, instead of skipping them",
 		},
 		&cli.StringSliceFlag{
 			Name:    "front-matter",
@@ -56,6 +79,86 @@ func main() {
 			Usage:   "Name of Front Matter attribute to use for tags (so that taxonomy in Hugo can be used)",
 			Value:   "tags",
 		},
+		&cli.StringFlag{
+			Name:  "anchor-style",
+			Usage: "Algorithm used for links to headings, must match 'autoHeadingIDType' of Hugo (github or blackfriday)",
+			Value: string(omh.AnchorStyleGitHub),
+		},
+		&cli.StringFlag{
+			Name:  "link-mode",
+			Usage: "How links to notes are rendered: url (absolute URL), ref or relref (Hugo shortcodes that are resolved on build)",
+			Value: string(omh.LinkURL),
+		},
+		&cli.StringFlag{
+			Name:  "embed-mode",
+			Usage: "How embedded notes are rendered: inline (splice content into embedding note) or shortcode (render '{{< embed \"path\" >}}')",
+			Value: string(omh.EmbedInline),
+		},
+		&cli.IntFlag{
+			Name:  "embed-depth",
+			Usage: "Maximum depth of recursively inlined embedded notes",
+			Value: omh.DefaultEmbedDepth,
+		},
+		&cli.StringSliceFlag{
+			Name:  "embed-kind",
+			Usage: "How embedded files with an extension are rendered (image, audio, video, pdf or file), in the form `.ext:kind`",
+		},
+		&cli.StringFlag{
+			Name:  "sized-images",
+			Usage: "How embedded images with dimensions are rendered: html ('<img>' tag) or figure (Hugo figure shortcode)",
+			Value: string(omh.SizedImageHTML),
+		},
+		&cli.StringFlag{
+			Name:  "callouts",
+			Usage: "How callouts are rendered: blockquote (alert syntax for Hugo render hooks), shortcode (render '{{< callout type=\"...\" >}}') or html ('<details>' if foldable, '<div>' otherwise)",
+			Value: string(omh.CalloutBlockquote),
+		},
+		&cli.StringSliceFlag{
+			Name:  "disable-transform",
+			Usage: "Name of inline syntax transform to disable (highlight)",
+		},
+		&cli.BoolFlag{
+			Name:  "expand-tags",
+			Usage: "Whether to add all ancestors of nested tags ('a/b/c' adds 'a' and 'a/b') to the Front Matter tags",
+		},
+		&cli.BoolFlag{
+			Name:  "tag-links",
+			Usage: "Whether to rewrite inline tags ('#some-tag') into links to the Hugo taxonomy term page",
+		},
+		&cli.BoolFlag{
+			Name:  "html-comments",
+			Usage: "Whether to render Obsidian comments ('%% ... %%') as HTML comments, instead of removing them",
+		},
+		&cli.BoolFlag{
+			Name:  "hugo-aliases",
+			Usage: "Whether to render aliases of notes as Hugo aliases, that redirect to the note",
+		},
+		&cli.StringFlag{
+			Name:  "backlinks-key",
+			Usage: "Name of Front Matter attribute to render backlinks from other notes in (no backlinks, if unset)",
+		},
+		&cli.StringFlag{
+			Name:  "graph-file",
+			Usage: "Name of JSON file in static sub-path, that the graph of all notes and their links is written to (no graph, if unset)",
+		},
+		&cli.StringFlag{
+			Name:  "report",
+			Usage: "Path of JSON file, that the report of the conversion is written to (no report, if unset)",
+		},
+		&cli.BoolFlag{
+			Name:  "strict",
+			Usage: "Whether to fail on unresolved links, missing embeds, unparsable dates and duplicate titles, instead of only logging warnings",
+		},
+		&cli.StringFlag{
+			Name:  "filtered-links",
+			Usage: "How links to filtered notes are rendered: text (plain title), private (link with 'private' CSS class), placeholder (replaced with --filtered-placeholder) or fail (abort)",
+			Value: string(omh.FilteredLinkText),
+		},
+		&cli.StringFlag{
+			Name:  "filtered-placeholder",
+			Usage: "Text that replaces links to filtered notes, if --filtered-links is placeholder",
+			Value: omh.DefaultFilteredPlaceholder,
+		},
 		&cli.BoolFlag{
 			Name:    "recursive",
 			Aliases: []string{"R"},
@@ -78,17 +181,36 @@ func main() {
 			log.SetLevel(log.DebugLevel)
 		}
 
-		recurse := c.Bool("recursive")
-		directory, err := omh.LoadObsidianDirectory(c.String("obsidian-root"), createFilter(c), recurse)
+		// time zone is required by date comparisons in the selection
+		timeZone, err := time.LoadLocation(c.String("time-zone"))
+		if err != nil {
+			return fmt.Errorf("failed to parse time zone: %w", err)
+		}
+		omh.TimeZone = timeZone
+
+		filter, err := createFilter(c)
 		if err != nil {
 			return err
 		}
 
-		timeZone, err := time.LoadLocation(c.String("time-zone"))
+		publishMode := omh.PublishMode(c.String("publish-mode"))
+		if publishMode != omh.PublishIgnore && publishMode != omh.PublishRequired && publishMode != omh.PublishRespect {
+			return fmt.Errorf("unsupported publish mode: %s", publishMode)
+		}
+
+		loader := omh.ObsidianLoader{
+			Filter:      filter,
+			Recurse:     c.Bool("recursive"),
+			PublishMode: publishMode,
+			PublishKey:  c.String("publish-key"),
+			Ignore:      c.StringSlice("ignore"),
+
+			WithoutFrontMatter: c.Bool("without-front-matter"),
+		}
+		directory, err := loader.Load(c.String("obsidian-root"))
 		if err != nil {
-			return fmt.Errorf("failed to parse time zone: %w", err)
+			return err
 		}
-		omh.TimeZone = timeZone
 
 		// is there additional front matter?
 		addFrontMatter := make(map[string]interface{})
@@ -97,6 +219,70 @@ func main() {
 			addFrontMatter[kv[0]] = kv[1]
 		}
 
+		anchorStyle := omh.AnchorStyle(c.String("anchor-style"))
+		if anchorStyle != omh.AnchorStyleGitHub && anchorStyle != omh.AnchorStyleBlackfriday {
+			return fmt.Errorf("unsupported anchor style: %s", anchorStyle)
+		}
+
+		linkMode := omh.LinkMode(c.String("link-mode"))
+		if linkMode != omh.LinkURL && linkMode != omh.LinkRef && linkMode != omh.LinkRelRef {
+			return fmt.Errorf("unsupported link mode: %s", linkMode)
+		}
+
+		embedMode := omh.EmbedMode(c.String("embed-mode"))
+		if embedMode != omh.EmbedInline && embedMode != omh.EmbedShortcode {
+			return fmt.Errorf("unsupported embed mode: %s", embedMode)
+		}
+
+		sizedImages := omh.SizedImageMode(c.String("sized-images"))
+		if sizedImages != omh.SizedImageHTML && sizedImages != omh.SizedImageFigure {
+			return fmt.Errorf("unsupported sized images mode: %s", sizedImages)
+		}
+
+		callouts := omh.CalloutMode(c.String("callouts"))
+		if callouts != omh.CalloutBlockquote && callouts != omh.CalloutShortcode && callouts != omh.CalloutHTML {
+			return fmt.Errorf("unsupported callout mode: %s", callouts)
+		}
+
+		filteredLinks := omh.FilteredLinkPolicy(c.String("filtered-links"))
+		switch filteredLinks {
+		case omh.FilteredLinkText, omh.FilteredLinkPrivate, omh.FilteredLinkPlaceholder, omh.FilteredLinkFail:
+		default:
+			return fmt.Errorf("unsupported filtered links policy: %s", filteredLinks)
+		}
+
+		// are there additional embedded file kinds?
+		embedKinds := make(map[string]omh.EmbedKind)
+		for _, kind := range c.StringSlice("embed-kind") {
+			kv := strings.SplitN(kind, ":", 2)
+			if len(kv) != 2 {
+				return fmt.Errorf("invalid embed kind: %s", kind)
+			}
+			switch omh.EmbedKind(kv[1]) {
+			case omh.EmbedImage, omh.EmbedAudio, omh.EmbedVideo, omh.EmbedPDF, omh.EmbedFile:
+			default:
+				return fmt.Errorf("unsupported embed kind: %s", kv[1])
+			}
+			// extensions are matched with leading dot, like `.mp3`, but may be given without
+			ext := strings.ToLower(strings.TrimSpace(kv[0]))
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			if ext == "." {
+				return fmt.Errorf("invalid embed kind: %s", kind)
+			}
+			embedKinds[ext] = omh.EmbedKind(kv[1])
+		}
+
+		// are there disabled inline transforms?
+		inlineTransforms := make(map[string]omh.InlineTransform)
+		for _, name := range c.StringSlice("disable-transform") {
+			if _, ok := omh.DefaultInlineTransforms[name]; !ok {
+				return fmt.Errorf("unsupported inline transform: %s", name)
+			}
+			inlineTransforms[name] = nil
+		}
+
 		converter := &omh.Converter{
 			ObsidianRoot: directory,
 			HugoRoot:     c.String("hugo-root"),
@@ -105,10 +291,32 @@ func main() {
 			ConvertName: func(name string) (link string) {
 				return omh.Sanitize(strcase.ToKebab(name))
 			},
-			TagsKey: c.String("tags-key"),
+			TagsKey:             c.String("tags-key"),
+			AnchorStyle:         anchorStyle,
+			LinkMode:            linkMode,
+			EmbedMode:           embedMode,
+			EmbedDepth:          c.Int("embed-depth"),
+			EmbedKinds:          embedKinds,
+			SizedImages:         sizedImages,
+			CalloutMode:         callouts,
+			InlineTransforms:    inlineTransforms,
+			ExpandTags:          c.Bool("expand-tags"),
+			TagLinks:            c.Bool("tag-links"),
+			HTMLComments:        c.Bool("html-comments"),
+			HugoAliases:         c.Bool("hugo-aliases"),
+			BacklinksKey:        c.String("backlinks-key"),
+			GraphFile:           c.String("graph-file"),
+			Strict:              c.Bool("strict"),
+			ReportFile:          c.String("report"),
+			FilteredLinks:       filteredLinks,
+			FilteredPlaceholder: c.String("filtered-placeholder"),
 		}
 
-		return converter.Run()
+		err = converter.Run()
+		if c.String("report") != "" {
+			fmt.Print(converter.Report().Summary())
+		}
+		return err
 	}
 
 	if err := app.Run(os.Args); err != nil {
@@ -116,13 +324,20 @@ func main() {
 	}
 }
 
-func createFilter(c *cli.Context) omh.ObsidianFilter {
+func createFilter(c *cli.Context) (omh.ObsidianFilter, error) {
 	filters := make([]omh.ObsidianFilter, 0)
+	if expression := c.String("select"); expression != "" {
+		selection, err := omh.ParseSelection(expression)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse selection: %w", err)
+		}
+		filters = append(filters, selection.Filter(c.String("obsidian-root")))
+	}
+
 	if includes := c.StringSlice("include-tag"); len(includes) > 0 {
-		included := strsToBoolMap(includes)
 		filters = append(filters, func(note omh.ObsidianNote) bool {
-			for _, tag := range note.FrontMatter.Strings("tags") {
-				if included[tag] {
+			for _, tag := range note.Tags() {
+				if matchesAny(tag, includes) {
 					return true
 				}
 			}
@@ -132,9 +347,8 @@ func createFilter(c *cli.Context) omh.ObsidianFilter {
 
 	if excludes := c.StringSlice("exclude-tag"); len(excludes) > 0 {
 		filters = append(filters, func(note omh.ObsidianNote) bool {
-			excluded := strsToBoolMap(excludes)
-			for _, tag := range note.FrontMatter.Strings("tags") {
-				if excluded[tag] {
+			for _, tag := range note.Tags() {
+				if matchesAny(tag, excludes) {
 					return false
 				}
 			}
@@ -143,7 +357,7 @@ func createFilter(c *cli.Context) omh.ObsidianFilter {
 	}
 
 	if len(filters) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	return func(note omh.ObsidianNote) bool {
@@ -153,7 +367,7 @@ func createFilter(c *cli.Context) omh.ObsidianFilter {
 			}
 		}
 		return true
-	}
+	}, nil
 }
 
 func loadTimeZone() string {
@@ -164,12 +378,13 @@ func loadTimeZone() string {
 	return tz
 }
 
-func strsToBoolMap(strs []string) map[string]bool {
-	r := make(map[string]bool)
-	for _, str := range strs {
-		r[str] = true
+func matchesAny(tag string, filters []string) bool {
+	for _, filter := range filters {
+		if omh.TagMatches(tag, filter) {
+			return true
+		}
 	}
-	return r
+	return false
 }
 
 func todo() {
@@ -0,0 +1,55 @@
+package omh
+
+import (
+	"strings"
+	"unicode"
+)
+
+// AnchorStyle is the algorithm Hugo uses to generate heading IDs (see `markup.goldmark.parser.autoHeadingIDType`)
+type AnchorStyle string
+
+const (
+	// AnchorStyleGitHub is the default heading ID algorithm of Hugo's goldmark renderer
+	AnchorStyleGitHub AnchorStyle = "github"
+
+	// AnchorStyleBlackfriday is the heading ID algorithm of the legacy blackfriday renderer
+	AnchorStyleBlackfriday AnchorStyle = "blackfriday"
+)
+
+// Anchor returns the heading ID, that Hugo would generate for the heading text
+func (style AnchorStyle) Anchor(heading string) string {
+	if style == AnchorStyleBlackfriday {
+		return blackfridayAnchor(heading)
+	}
+	return githubAnchor(heading)
+}
+
+func githubAnchor(heading string) string {
+	var anchor strings.Builder
+	for _, r := range strings.TrimSpace(heading) {
+		switch {
+		case r == '-' || r == ' ':
+			anchor.WriteRune('-')
+		case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			anchor.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return anchor.String()
+}
+
+func blackfridayAnchor(heading string) string {
+	var anchor strings.Builder
+	dash := false
+	for _, r := range heading {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			if dash && anchor.Len() > 0 {
+				anchor.WriteRune('-')
+			}
+			dash = false
+			anchor.WriteRune(unicode.ToLower(r))
+		} else {
+			dash = true
+		}
+	}
+	return anchor.String()
+}
@@ -0,0 +1,35 @@
+package omh_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	omh "github.com/ukautz/obsidian-meets-hugo/pkg"
+)
+
+func TestAnchorStyle_Anchor(t *testing.T) {
+	tests := map[string]struct {
+		from        string
+		github      string
+		blackfriday string
+	}{
+		"empty":      {"", "", ""},
+		"words":      {"Setup Steps", "setup-steps", "setup-steps"},
+		"dashes":     {"Foo - Bar", "foo---bar", "foo-bar"},
+		"underscore": {"foo_bar", "foo_bar", "foo-bar"},
+		"punctuated": {"What's new?", "whats-new", "what-s-new"},
+		"trimmed":    {"  Padded  ", "padded", "padded"},
+		"unicode":    {"Über Straße 2", "über-straße-2", "über-straße-2"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.github, omh.AnchorStyleGitHub.Anchor(test.from))
+			assert.Equal(t, test.blackfriday, omh.AnchorStyleBlackfriday.Anchor(test.from))
+		})
+	}
+}
+
+func TestAnchorStyle_Anchor_DefaultsToGitHub(t *testing.T) {
+	assert.Equal(t, "foo---bar", omh.AnchorStyle("").Anchor("Foo - Bar"))
+}
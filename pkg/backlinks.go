@@ -0,0 +1,187 @@
+package omh
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// BacklinkExcerptLength is the maximum length (in characters) of the excerpt of a backlink
+const BacklinkExcerptLength = 200
+
+// Backlink is a link from another note, that is rendered in the front matter of the linked note
+type Backlink struct {
+	Title   string `yaml:"title"`
+	URL     string `yaml:"url"`
+	Excerpt string `yaml:"excerpt,omitempty"`
+}
+
+// noteLink is a link from a note to another note or file, as found in the content of the note
+type noteLink struct {
+
+	// Target is the Hugo compatible web link of the linked note or file, empty if the link can not be resolved
+	Target string
+
+	// Raw is the link, as it is written in the content
+	Raw string
+
+	// Heading is the referenced heading in the linked note, if any
+	Heading string
+
+	// Block is the ID of the referenced block in the linked note, if any
+	Block string
+
+	// Embed is whether the link embeds the target
+	Embed bool
+
+	// Filtered is whether the target is a note, that was rejected by the filter
+	Filtered bool
+
+	// Line is the line number of the link within the content of the note
+	Line int
+
+	// Excerpt is the line of the content, that contains the link, in plain text
+	Excerpt string
+}
+
+var (
+	// excerptLink matches Obsidian and Markdown links, to be replaced with their titles in excerpts
+	excerptLink = regexp.MustCompile(`!?\[\[.+?\]\]|!?\[([^\]]*)\]\(([^)]*)\)`)
+
+	// excerptMarker matches leading list, quote and heading markers, to be removed from excerpts
+	excerptMarker = regexp.MustCompile(`^(?:\s*(?:[-+*>#]+|\d+[.)])\s+)+`)
+)
+
+// scanLinks returns all links to notes and files in the content of the note located at target, including links to
+// filtered notes and links, that can not be resolved
+func (c Converter) scanLinks(note ObsidianNote, target string) []noteLink {
+	links := make([]noteLink, 0)
+	visible := blankComments(note.Content)
+	filtered := c.filteredReferences(target, visible)
+	add := func(offset int, link noteLink) {
+		link.Line = strings.Count(note.Content[:offset], "\n") + 1
+		link.Excerpt = c.excerpt(visible, offset, target, filtered)
+		links = append(links, link)
+	}
+
+	offset := 0
+	for _, segment := range scanMarkdown(note.Content, true) {
+		if segment.Kind == markdownProse {
+			for _, loc := range obsidianLink.FindAllStringIndex(segment.Text, -1) {
+				raw := segment.Text[loc[0]:loc[1]]
+				link := ParseObsidianLink(raw)
+				found := noteLink{Raw: raw, Embed: link.Embed, Heading: link.Heading, Block: link.Block}
+				if link.Target == "" {
+					if link.Block == "" {
+						continue
+					}
+					found.Target = target
+				} else if linkTarget, ok := c.resolveLink(target, link.Target); ok {
+					found.Target = linkTarget
+				} else if linkTarget, ok := c.resolveFilteredLink(target, link.Target); ok {
+					found.Target, found.Filtered = linkTarget, true
+				}
+				add(offset+loc[0], found)
+			}
+		}
+		offset += len(segment.Text)
+	}
+
+	// Markdown links are found in whole blocks, so that links with inline code in their text are recognized
+	offset = 0
+	for _, segment := range scanMarkdown(note.Content, false) {
+		if segment.Kind == markdownProse {
+			opaque := opaqueSpans(segment.Text)
+			for _, re := range []*regexp.Regexp{markdownLink, markdownReference} {
+				for _, loc := range re.FindAllStringSubmatchIndex(segment.Text, -1) {
+					if inSpans(opaque, loc[0]) || inSpans(opaque, loc[4]) {
+						continue
+					}
+					destination := strings.TrimSuffix(strings.TrimPrefix(segment.Text[loc[4]:loc[5]], "<"), ">")
+					found := noteLink{Raw: segment.Text[loc[0]:loc[1]], Embed: strings.HasPrefix(segment.Text[loc[0]:], "!")}
+					if linkTarget, _, ok := c.resolveMarkdownDestination(target, destination); ok && linkTarget != "" {
+						found.Target = linkTarget
+					} else if linkTarget, _, ok := c.resolveFilteredMarkdownDestination(target, destination); ok {
+						found.Target, found.Filtered = linkTarget, true
+					} else if ok || !strings.HasSuffix(strings.SplitN(destination, "#", 2)[0], ".md") {
+						continue
+					}
+					add(offset+loc[0], found)
+				}
+			}
+		}
+		offset += len(segment.Text)
+	}
+	sort.SliceStable(links, func(i, j int) bool {
+		return links[i].Line < links[j].Line
+	})
+
+	return links
+}
+
+// backlinkMap returns the backlinks of all notes, by the Hugo compatible web link of the linked note
+func (c Converter) backlinkMap() map[string][]Backlink {
+	backlinks := make(map[string][]Backlink)
+	c.ObsidianRoot.walkNotes(c.ConvertName, func(note ObsidianNote, vaultPath, target string) {
+		linked := map[string]bool{target: true}
+		for _, link := range c.scanLinks(note, target) {
+			if linked[link.Target] || link.Filtered || !strings.HasSuffix(link.Target, "/") {
+				continue
+			}
+			linked[link.Target] = true
+			backlinks[link.Target] = append(backlinks[link.Target], Backlink{
+				Title:   note.Title,
+				URL:     "/" + c.SubPath + "/" + target,
+				Excerpt: link.Excerpt,
+			})
+		}
+	})
+	return backlinks
+}
+
+// excerpt returns the line of the content of the note located at target at offset in plain text, shortened to
+// BacklinkExcerptLength. Links to filtered notes are rendered as plain text, as configured in FilteredLinks, so that
+// their titles are not published with placeholders.
+func (c Converter) excerpt(content string, offset int, target string, filtered map[string]string) string {
+	start := strings.LastIndex(content[:offset], "\n") + 1
+	end := len(content)
+	if i := strings.Index(content[offset:], "\n"); i > -1 {
+		end = offset + i
+	}
+
+	plain := c
+	if plain.FilteredLinks != FilteredLinkPlaceholder {
+		plain.FilteredLinks = FilteredLinkText
+	}
+	line := plain.convertFilteredReferences(content[start:end], filtered)
+	line = replaceLinks(excerptLink, line, func(match []string) string {
+		if strings.Contains(match[0], "[[") {
+			link := ParseObsidianLink(match[0])
+			if link.Target == "" {
+				return link.DisplayTitle()
+			} else if _, ok := c.resolveLink(target, link.Target); !ok {
+				if filteredTarget, ok := c.resolveFilteredLink(target, link.Target); ok {
+					return plain.filteredLink(link.DisplayTitle(), filteredTarget)
+				}
+			}
+			return link.DisplayTitle()
+		}
+		if fields := strings.Fields(match[2]); len(fields) > 0 {
+			destination := strings.TrimSuffix(strings.TrimPrefix(fields[0], "<"), ">")
+			if _, _, ok := c.resolveMarkdownDestination(target, destination); !ok {
+				if filteredTarget, _, ok := c.resolveFilteredMarkdownDestination(target, destination); ok {
+					return plain.filteredLink(match[1], filteredTarget)
+				}
+			}
+		}
+		return match[1]
+	})
+	line = obsidianBlockID.ReplaceAllString(line, "")
+	line = strings.TrimSpace(excerptMarker.ReplaceAllString(line, ""))
+	if utf8.RuneCountInString(line) > BacklinkExcerptLength {
+		line = string([]rune(line)[:BacklinkExcerptLength-1]) + "…"
+	}
+
+	return line
+}
@@ -0,0 +1,48 @@
+package omh_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	omh "github.com/ukautz/obsidian-meets-hugo/pkg"
+)
+
+func TestConverter_Run_Backlinks(t *testing.T) {
+	notes := map[string]string{
+		"Target.md": note("Links to [[Target#Self]] are ignored"),
+		"Sub/Wiki.md": note(
+			"- See [[Target|the target]] for **details** ^block",
+			"- and [[Target#Heading]] again",
+		),
+		"Sub/Markdown.md": note("> Embedding ![[Target]] and [[Image.png]]", "", "```", "[[Other]]", "```"),
+		"Other.md":        note("[Relative](Target.md) with [[Missing]] ", "", "`[[Sub/Wiki]]`"),
+		"Image.png":       "",
+	}
+
+	pages := convertVaultPages(t, notes, nil)
+	assert.NotContains(t, pages["target.md"], "backlinks")
+
+	pages = convertVaultPages(t, notes, func(converter *omh.Converter) {
+		converter.BacklinksKey = "backlinks"
+	})
+	assert.Equal(t, strings.Join([]string{
+		"---",
+		"backlinks:",
+		"- title: Other",
+		"  url: /sub-path/other/",
+		"  excerpt: Relative with Missing",
+		"- title: Markdown",
+		"  url: /sub-path/sub/markdown/",
+		"  excerpt: Embedding Target and Image.png",
+		"- title: Wiki",
+		"  url: /sub-path/sub/wiki/",
+		"  excerpt: See the target for **details**",
+		"tags:",
+		"- any",
+		"title: Target",
+		"---",
+	}, "\n"), pages["target.md"][:strings.Index(pages["target.md"], "---\n\n")+3])
+	assert.NotContains(t, pages["other.md"], "backlinks")
+	assert.NotContains(t, pages["sub/wiki.md"], "backlinks")
+}
@@ -0,0 +1,122 @@
+package omh
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// CalloutMode is how Obsidian callouts (`> [!warning] Title`) are rendered in Hugo
+type CalloutMode string
+
+const (
+	// CalloutBlockquote renders callouts as blockquotes in the alert syntax, that is parsed by the blockquote render
+	// hooks of Hugo 0.132+, like `> [!warning]- Title`
+	CalloutBlockquote CalloutMode = "blockquote"
+
+	// CalloutShortcode renders callouts with a `{{< callout type="warning" title="Title" >}}` shortcode, with the
+	// additional `fold="open"` or `fold="closed"` parameter for foldable callouts. The shortcode must be provided by the
+	// Hugo setup.
+	CalloutShortcode CalloutMode = "shortcode"
+
+	// CalloutHTML renders foldable callouts as HTML `<details>` and all other callouts as HTML `<div>`, with the
+	// `callout` CSS class and the type in the `data-callout` attribute. Requires `markup.goldmark.renderer.unsafe` to
+	// be enabled in Hugo.
+	CalloutHTML CalloutMode = "html"
+)
+
+var (
+	// calloutHeader matches the first line of a callout, like `> [!warning]- Some Title`
+	calloutHeader = regexp.MustCompile(`^ {0,3}>[ \t]?\[!([a-zA-Z0-9_-]+)\]([+-]?)[ \t]*(.*?)[ \t]*$`)
+
+	// blockquoteLine matches lines, that continue a blockquote
+	blockquoteLine = regexp.MustCompile(`^ {0,3}>[ \t]?`)
+)
+
+// callout is a parsed Obsidian callout
+type callout struct {
+	Type  string
+	Fold  string
+	Title string
+	Body  string
+}
+
+// convertCallouts rewrites all (nested) callouts in the content, outside of code blocks, as configured in CalloutMode
+func (c Converter) convertCallouts(content string) string {
+	return mapBlocks(content, func(text string) string {
+		lines := strings.SplitAfter(text, "\n")
+		var converted strings.Builder
+		for i := 0; i < len(lines); {
+			match := calloutHeader.FindStringSubmatch(strings.TrimRight(lines[i], "\r\n"))
+			if match == nil {
+				converted.WriteString(lines[i])
+				i++
+				continue
+			}
+
+			end := i + 1
+			body := make([]string, 0)
+			for end < len(lines) && blockquoteLine.MatchString(lines[end]) {
+				body = append(body, blockquoteLine.ReplaceAllString(lines[end], ""))
+				end++
+			}
+
+			rendered := c.renderCallout(callout{
+				Type:  strings.ToLower(match[1]),
+				Fold:  match[2],
+				Title: match[3],
+				Body:  strings.TrimRight(c.convertCallouts(strings.Join(body, "")), "\r\n"),
+			})
+			converted.WriteString(rendered)
+			if strings.HasSuffix(lines[end-1], "\n") {
+				converted.WriteString("\n")
+			}
+			i = end
+		}
+		return converted.String()
+	})
+}
+
+// renderCallout renders the callout, without trailing newline, as configured in CalloutMode
+func (c Converter) renderCallout(callout callout) string {
+	switch c.CalloutMode {
+	case CalloutShortcode:
+		params := fmt.Sprintf("type=%q", callout.Type)
+		if callout.Title != "" {
+			params += fmt.Sprintf(" title=%q", callout.Title)
+		}
+		if callout.Fold == "-" {
+			params += ` fold="closed"`
+		} else if callout.Fold == "+" {
+			params += ` fold="open"`
+		}
+		return fmt.Sprintf("{{< callout %s >}}\n%s\n{{< /callout >}}", params, callout.Body)
+
+	case CalloutHTML:
+		title := callout.Title
+		if title == "" {
+			title = strings.ToUpper(callout.Type[:1]) + callout.Type[1:]
+		}
+		attributes := fmt.Sprintf(`class="callout" data-callout="%s"`, html.EscapeString(callout.Type))
+		if callout.Fold == "" {
+			return fmt.Sprintf("<div %s>\n<div class=\"callout-title\">%s</div>\n\n%s\n\n</div>",
+				attributes, html.EscapeString(title), callout.Body)
+		} else if callout.Fold == "+" {
+			attributes += " open"
+		}
+		return fmt.Sprintf("<details %s>\n<summary>%s</summary>\n\n%s\n\n</details>",
+			attributes, html.EscapeString(title), callout.Body)
+
+	default:
+		header := strings.TrimSpace(fmt.Sprintf("> [!%s]%s %s", callout.Type, callout.Fold, callout.Title))
+		if callout.Body == "" {
+			return header
+		}
+		lines := strings.Split(callout.Body, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+		return header + "\n" + strings.Join(lines, "\n")
+	}
+}
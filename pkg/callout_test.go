@@ -0,0 +1,58 @@
+package omh_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	omh "github.com/ukautz/obsidian-meets-hugo/pkg"
+)
+
+func TestConverter_Run_Callouts(t *testing.T) {
+	content := note(
+		"Before",
+		"",
+		"> [!Warning] Mind the [[Gap]]",
+		"> Some text",
+		">",
+		"> > [!tip]-",
+		"> > Nested",
+		"",
+		"> [!note]+ Open",
+		"> ```",
+		"> > [!note] in code",
+		"> ```",
+		"",
+		"```",
+		"> [!note] not a callout",
+		"```",
+		"",
+		"> A plain quote",
+	)
+	notes := map[string]string{"Index.md": content, "Gap.md": note("Gap")}
+
+	expects := map[omh.CalloutMode]string{
+		omh.CalloutBlockquote: "Before\n\n" +
+			"> [!warning] Mind the [Gap](/sub-path/gap/)\n> Some text\n>\n> > [!tip]-\n> > Nested\n\n" +
+			"> [!note]+ Open\n> ```\n> > [!note] in code\n> ```\n\n" +
+			"```\n> [!note] not a callout\n```\n\n> A plain quote",
+		omh.CalloutShortcode: "Before\n\n" +
+			"{{< callout type=\"warning\" title=\"Mind the [Gap](/sub-path/gap/)\" >}}\nSome text\n\n" +
+			"{{< callout type=\"tip\" fold=\"closed\" >}}\nNested\n{{< /callout >}}\n{{< /callout >}}\n\n" +
+			"{{< callout type=\"note\" title=\"Open\" fold=\"open\" >}}\n```\n> [!note] in code\n```\n{{< /callout >}}\n\n" +
+			"```\n> [!note] not a callout\n```\n\n> A plain quote",
+		omh.CalloutHTML: "Before\n\n" +
+			"<div class=\"callout\" data-callout=\"warning\">\n<div class=\"callout-title\">Mind the [Gap](/sub-path/gap/)</div>\n\nSome text\n\n" +
+			"<details class=\"callout\" data-callout=\"tip\">\n<summary>Tip</summary>\n\nNested\n\n</details>\n\n</div>\n\n" +
+			"<details class=\"callout\" data-callout=\"note\" open>\n<summary>Open</summary>\n\n```\n> [!note] in code\n```\n\n</details>\n\n" +
+			"```\n> [!note] not a callout\n```\n\n> A plain quote",
+	}
+	for mode, expect := range expects {
+		mode, expect := mode, expect
+		t.Run(string(mode), func(t *testing.T) {
+			pages := convertVault(t, notes, func(converter *omh.Converter) {
+				converter.CalloutMode = mode
+			})
+			assert.Equal(t, expect, pages["index.md"])
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package omh
+
+import "strings"
+
+// convertComments removes all Obsidian comments (`%% ... %%`) outside of code from the content, or turns them into HTML
+// comments, if HTMLComments is enabled
+func (c Converter) convertComments(content string) string {
+	var converted strings.Builder
+	for _, segment := range scanMarkdown(content, true) {
+		if segment.Kind != markdownComment {
+			converted.WriteString(segment.Text)
+		} else if c.HTMLComments {
+			text := strings.TrimSuffix(strings.TrimPrefix(segment.Text, "%%"), "%%")
+			converted.WriteString("<!--" + strings.ReplaceAll(text, "--", "- -") + "-->")
+		}
+	}
+	return converted.String()
+}
+
+// blankComments replaces all characters of Obsidian comments in the content with spaces, keeping line breaks, so that
+// offsets and line numbers stay the same
+func blankComments(content string) string {
+	var blanked strings.Builder
+	for _, segment := range scanMarkdown(content, true) {
+		if segment.Kind != markdownComment {
+			blanked.WriteString(segment.Text)
+			continue
+		}
+		for _, r := range segment.Text {
+			if r == '\n' || r == '\r' {
+				blanked.WriteRune(r)
+			} else {
+				blanked.WriteByte(' ')
+			}
+		}
+	}
+	return blanked.String()
+}
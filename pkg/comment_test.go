@@ -0,0 +1,70 @@
+package omh_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	omh "github.com/ukautz/obsidian-meets-hugo/pkg"
+)
+
+func TestConverter_Run_Comments(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		html    bool
+		expect  string
+	}{
+		"inline": {
+			content: "Public %%private [[Target]]%% text",
+			expect:  "Public  text",
+		},
+		"multi-line": {
+			content: "Public\n\n%%\nprivate\n\nremarks\n%%\n\n[[Target]]",
+			expect:  "Public\n\n\n\n[Target](/sub-path/target/)",
+		},
+		"indented": {
+			content: "start\n\n%%\nprivate\n\n    indented secret\n%%\n\npublic text",
+			expect:  "start\n\n\n\npublic text",
+		},
+		"fenced": {
+			content: "start %%private\n\n```\nfenced secret\n```\n%% public text",
+			expect:  "start  public text",
+		},
+		"unclosed": {
+			content: "Public %%private\n\nstill private",
+			expect:  "Public ",
+		},
+		"code": {
+			content: "`%%code%%`\n\n```\n%%code%%\n```",
+			expect:  "`%%code%%`\n\n```\n%%code%%\n```",
+		},
+		"html": {
+			content: "Public %%private -- remark%% text",
+			html:    true,
+			expect:  "Public <!--private - - remark--> text",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			pages := convertVault(t, map[string]string{
+				"Index.md":  note(test.content),
+				"Target.md": note("Target"),
+			}, func(converter *omh.Converter) {
+				converter.HTMLComments = test.html
+			})
+			assert.Equal(t, test.expect, pages["index.md"])
+		})
+	}
+}
+
+func TestConverter_Run_CommentsInBacklinks(t *testing.T) {
+	pages := convertVaultPages(t, map[string]string{
+		"Index.md":  note("See [[Target]] %%private remark%%", "%%[[Target]] in comment%%"),
+		"Target.md": note("Target"),
+	}, func(converter *omh.Converter) {
+		converter.BacklinksKey = "backlinks"
+	})
+	assert.Contains(t, pages["target.md"], "excerpt: See Target\n")
+	assert.NotContains(t, pages["target.md"], "private")
+}
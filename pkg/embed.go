@@ -0,0 +1,287 @@
+package omh
+
+import (
+	"fmt"
+	"html"
+	"path"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// EmbedMode is how embedded notes (`![[Some Note]]`) are rendered in Hugo
+type EmbedMode string
+
+const (
+	// EmbedInline splices the content of the embedded note (or the embedded section thereof) into the embedding note
+	EmbedInline EmbedMode = "inline"
+
+	// EmbedShortcode renders embedded notes as `{{< embed "sub-path/some-note.md" >}}` shortcode, with the anchor of
+	// the embedded heading or block as optional second parameter. The shortcode must be provided by the Hugo setup.
+	EmbedShortcode EmbedMode = "shortcode"
+)
+
+// DefaultEmbedDepth is the maximum depth of inlined embedded notes, if not configured otherwise
+const DefaultEmbedDepth = 5
+
+// EmbedKind is the kind of an embedded (static) file, that determines how it is rendered in Hugo. Note that all kinds,
+// that render HTML, require `markup.goldmark.renderer.unsafe` to be enabled in Hugo.
+type EmbedKind string
+
+const (
+	// EmbedImage renders embedded files as Markdown image
+	EmbedImage EmbedKind = "image"
+
+	// EmbedAudio renders embedded files as HTML `<audio>` player
+	EmbedAudio EmbedKind = "audio"
+
+	// EmbedVideo renders embedded files as HTML `<video>` player
+	EmbedVideo EmbedKind = "video"
+
+	// EmbedPDF renders embedded files as HTML `<iframe>`
+	EmbedPDF EmbedKind = "pdf"
+
+	// EmbedFile renders embedded files as plain Markdown link
+	EmbedFile EmbedKind = "file"
+)
+
+// SizedImageMode is how embedded images with dimensions (`![[image.png|400x300]]`) are rendered in Hugo
+type SizedImageMode string
+
+const (
+	// SizedImageHTML renders sized images as HTML `<img>` with `width` and `height` attributes, which requires
+	// `markup.goldmark.renderer.unsafe` to be enabled in Hugo
+	SizedImageHTML SizedImageMode = "html"
+
+	// SizedImageFigure renders sized images with the Hugo built-in `figure` shortcode
+	SizedImageFigure SizedImageMode = "figure"
+)
+
+// DefaultEmbedKinds maps (lower case) file extensions to the kind of embedded files, as supported by Obsidian.
+// Files with extensions that are not listed are embedded as EmbedFile.
+var DefaultEmbedKinds = map[string]EmbedKind{
+	".avif": EmbedImage,
+	".bmp":  EmbedImage,
+	".gif":  EmbedImage,
+	".jpeg": EmbedImage,
+	".jpg":  EmbedImage,
+	".png":  EmbedImage,
+	".svg":  EmbedImage,
+	".webp": EmbedImage,
+	".3gp":  EmbedAudio,
+	".flac": EmbedAudio,
+	".m4a":  EmbedAudio,
+	".mp3":  EmbedAudio,
+	".ogg":  EmbedAudio,
+	".wav":  EmbedAudio,
+	".mkv":  EmbedVideo,
+	".mov":  EmbedVideo,
+	".mp4":  EmbedVideo,
+	".ogv":  EmbedVideo,
+	".webm": EmbedVideo,
+	".pdf":  EmbedPDF,
+}
+
+// embedNote returns the content of the note located at target, or the part of it, that the link with anchor embeds, and
+// true, or a link or shortcode instead, if the content can not be inlined. See convertContent for embedded.
+func (c Converter) embedNote(note ObsidianNote, target string, link ObsidianLink, anchor string, embedded []string) (string, bool) {
+	fallback := fmt.Sprintf("[%s](%s)", link.DisplayTitle(), c.linkURL(target, anchor))
+	logger := log.WithFields(log.Fields{
+		"embed-target": target + anchor,
+		"note":         note.Title,
+	})
+
+	if c.EmbedMode == EmbedShortcode {
+		if anchor != "" {
+			return fmt.Sprintf(`{{< embed "%s" "%s" >}}`, c.contentFile(target), anchor[1:]), false
+		}
+		return fmt.Sprintf(`{{< embed "%s" >}}`, c.contentFile(target)), false
+	}
+
+	depth := c.EmbedDepth
+	if depth <= 0 {
+		depth = DefaultEmbedDepth
+	}
+	if len(embedded) > depth {
+		logger.Warn("embed depth exceeded, linking instead")
+		return fallback, false
+	}
+
+	// embedding the whole note is circular, if any part of it is already embedded
+	key := target + anchor
+	for _, parent := range embedded {
+		if parent == key || (anchor == "" && strings.HasPrefix(parent, target+"#")) {
+			logger.Warn("circular embed, linking instead")
+			return fallback, false
+		}
+	}
+
+	content, ok := note.Content, true
+	if link.Block != "" {
+		content, ok = noteBlock(note.Content, link.Block)
+	} else if link.Heading != "" {
+		content, ok = noteSection(note.Content, link.Heading, c.AnchorStyle)
+	}
+	if !ok {
+		logger.Warn("missing section to embed, linking instead")
+		return fallback, false
+	}
+
+	return c.convertContent(note, target, content, append(embedded, key)), true
+}
+
+// embedBlock renders the inlined content, that replaces an embed between the text before and after it on the same
+// line, as a block of its own: separated by blank lines from that text and with the blockquote markers and list
+// indentation of the line on all lines. It returns the block, whether it is separated from the text before and the
+// length of the whitespace after the embed, that is replaced.
+func embedBlock(before, after, content string) (string, bool, int) {
+	match := markdownContainer.FindStringSubmatch(before)
+	prefix := match[1] + strings.Repeat(" ", len(match[2]))
+	blank := strings.TrimRight(prefix, " \t")
+
+	lines := strings.Split(strings.TrimRight(strings.TrimLeft(content, "\r\n"), "\r\n"), "\n")
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			lines[i] = blank
+		} else {
+			lines[i] = prefix + lines[i]
+		}
+	}
+	block := strings.Join(lines, "\n")
+
+	separated := strings.TrimSpace(before[len(match[0]):]) != ""
+	if separated {
+		block = "\n" + blank + "\n" + prefix + block
+	}
+
+	rest := 0
+	if i := strings.Index(after, "\n"); i > -1 {
+		after = after[:i]
+	}
+	if strings.TrimSpace(after) != "" {
+		block += "\n" + blank + "\n" + prefix
+		rest = len(after) - len(strings.TrimLeft(after, " \t"))
+	}
+
+	return block, separated, rest
+}
+
+func (c Converter) embedFile(target string, link ObsidianLink) string {
+	title := link.DisplayTitle()
+	url := c.linkURL(target, "")
+
+	switch c.embedKind(link.Target) {
+	case EmbedImage:
+		if link.Width > 0 {
+			return c.sizedImage(url, title, link.Width, link.Height)
+		}
+		return fmt.Sprintf("![%s](%s)", title, url)
+	case EmbedAudio:
+		return fmt.Sprintf(`<audio controls src="%s" title="%s"></audio>`, html.EscapeString(url), html.EscapeString(title))
+	case EmbedVideo:
+		return fmt.Sprintf(`<video controls src="%s" title="%s"></video>`, html.EscapeString(url), html.EscapeString(title))
+	case EmbedPDF:
+		return fmt.Sprintf(`<iframe src="%s" title="%s"></iframe>`, html.EscapeString(url), html.EscapeString(title))
+	default:
+		return fmt.Sprintf("[%s](%s)", title, url)
+	}
+}
+
+func (c Converter) sizedImage(url, title string, width, height int) string {
+	attributes := fmt.Sprintf(`src="%s" alt="%s" width="%d"`, html.EscapeString(url), html.EscapeString(title), width)
+	if height > 0 {
+		attributes += fmt.Sprintf(` height="%d"`, height)
+	}
+
+	if c.SizedImages == SizedImageFigure {
+		return fmt.Sprintf("{{< figure %s >}}", attributes)
+	}
+	return fmt.Sprintf("<img %s>", attributes)
+}
+
+// embedKind returns the kind of the file, as configured in EmbedKinds or DefaultEmbedKinds
+func (c Converter) embedKind(file string) EmbedKind {
+	ext := strings.ToLower(path.Ext(file))
+	if kind, ok := c.EmbedKinds[ext]; ok {
+		return kind
+	} else if kind, ok = DefaultEmbedKinds[ext]; ok {
+		return kind
+	}
+	return EmbedFile
+}
+
+// contentFile returns the path of the Hugo page of the target, relative to the `content` directory
+func (c Converter) contentFile(target string) string {
+	return path.Join(c.SubPath, strings.TrimSuffix(target, "/")) + ".md"
+}
+
+// noteSection returns the part of content, that starts with the heading and ends before the next heading of the
+// same or a higher level
+func noteSection(content, heading string, style AnchorStyle) (string, bool) {
+	anchor := style.Anchor(heading)
+	lines := strings.Split(content, "\n")
+	code := markdownCodeBlockLines(lines)
+
+	start, level := -1, 0
+	for i, line := range lines {
+		match := markdownHeading.FindStringSubmatch(line)
+		if code[i] || match == nil {
+			continue
+		} else if start > -1 && len(match[1]) <= level {
+			return strings.TrimSpace(strings.Join(lines[start:i], "\n")), true
+		} else if start == -1 && style.Anchor(match[2]) == anchor {
+			start, level = i, len(match[1])
+		}
+	}
+	if start == -1 {
+		return "", false
+	}
+
+	return strings.TrimSpace(strings.Join(lines[start:], "\n")), true
+}
+
+// noteBlock returns the paragraph (or other block) of content, that is marked with the block ID. Headings and list
+// items are blocks of their own, list items including their nested lines, unless the ID follows the whole list.
+func noteBlock(content, id string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	code := markdownCodeBlockLines(lines)
+	for i, line := range lines {
+		match := obsidianBlockID.FindStringSubmatch(line)
+		if code[i] || match == nil || match[2] != id {
+			continue
+		}
+
+		// the ID is either at the end of the block, or in an own line following the block
+		end := i + 1
+		ownLine := strings.TrimSpace(line) == "^"+id
+		if ownLine {
+			end = i
+			for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+				end--
+			}
+		}
+		if end > 0 && markdownHeading.MatchString(strings.TrimRight(lines[end-1], "\r")) {
+			return lines[end-1], true
+		}
+
+		start := end
+		for start > 0 && strings.TrimSpace(lines[start-1]) != "" && !markdownHeading.MatchString(strings.TrimRight(lines[start-1], "\r")) {
+			start--
+			if !ownLine && isListItem(lines[start]) {
+				indent := indentation(lines[start])
+				for end < len(lines) && strings.TrimSpace(lines[end]) != "" && indentation(lines[end]) > indent {
+					end++
+				}
+				break
+			}
+		}
+		return strings.Join(lines[start:end], "\n"), true
+	}
+
+	return "", false
+}
+
+// isListItem returns whether the line starts a (possibly nested) list item
+func isListItem(line string) bool {
+	return markdownListItem.MatchString(strings.TrimLeft(line, " \t"))
+}
@@ -0,0 +1,205 @@
+package omh_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	omh "github.com/ukautz/obsidian-meets-hugo/pkg"
+)
+
+func TestConverter_Run_EmbedNotes(t *testing.T) {
+	notes := map[string]string{
+		"Embedded.md": note(
+			"Intro with [[#Second]]",
+			"",
+			"# First",
+			"",
+			"First text ^the-block",
+			"",
+			"## Sub of First",
+			"",
+			"```",
+			"# not a heading",
+			"```",
+			"",
+			"# Second",
+			"",
+			"Second text",
+		),
+		"Sub/Image.png": "",
+	}
+
+	tests := map[string]struct {
+		content   string
+		configure func(converter *omh.Converter)
+		expect    string
+	}{
+		"whole note": {
+			content: "![[Embedded]]",
+			expect: "Intro with [Second](/sub-path/embedded/#second)\n\n# First\n\n" +
+				"First text <span id=\"the-block\"></span>\n\n## Sub of First\n\n```\n# not a heading\n```\n\n# Second\n\nSecond text",
+		},
+		"heading": {
+			content: "![[Embedded#First]]",
+			expect:  "# First\n\nFirst text <span id=\"the-block\"></span>\n\n## Sub of First\n\n```\n# not a heading\n```",
+		},
+		"block": {
+			content: "![[Embedded#^the-block]]",
+			expect:  "First text <span id=\"the-block\"></span>",
+		},
+		"within text": {
+			content: "Text ![[Embedded#Sub of First]] more text",
+			expect:  "Text\n\n## Sub of First\n\n```\n# not a heading\n```\n\nmore text",
+		},
+		"within quote": {
+			content: "> Quoted ![[Embedded#Sub of First]]\n> more",
+			expect:  "> Quoted\n>\n> ## Sub of First\n>\n> ```\n> # not a heading\n> ```\n> more",
+		},
+		"within callout": {
+			content: "> [!note] Title\n> ![[Embedded#Sub of First]]",
+			expect:  "> [!note] Title\n> ## Sub of First\n>\n> ```\n> # not a heading\n> ```",
+		},
+		"within list": {
+			content: "- Item ![[Embedded#Sub of First]]\n- Next",
+			expect:  "- Item\n\n  ## Sub of First\n\n  ```\n  # not a heading\n  ```\n- Next",
+		},
+		"after code in quote": {
+			content: "> Quote `x` ![[Embedded#Sub of First]] `y` tail",
+			expect:  "> Quote `x`\n>\n> ## Sub of First\n>\n> ```\n> # not a heading\n> ```\n>\n> `y` tail",
+		},
+		"after code in list": {
+			content: "- Item `x` ![[Embedded#Sub of First]]",
+			expect:  "- Item `x`\n\n  ## Sub of First\n\n  ```\n  # not a heading\n  ```",
+		},
+		"missing heading": {
+			content: "![[Embedded#Third]]",
+			expect:  "[Embedded > Third](/sub-path/embedded/#third)",
+		},
+		"static file": {
+			content: "![[Image.png]]",
+			expect:  "![Image.png](/sub-path/sub/image.png)",
+		},
+		"shortcode": {
+			content: "![[Embedded]] and ![[Embedded#Sub of First]]",
+			configure: func(converter *omh.Converter) {
+				converter.EmbedMode = omh.EmbedShortcode
+			},
+			expect: `{{< embed "sub-path/embedded.md" >}} and {{< embed "sub-path/embedded.md" "sub-of-first" >}}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			notes["Embedding.md"] = note(test.content)
+			pages := convertVault(t, notes, test.configure)
+			assert.Equal(t, test.expect, pages["embedding.md"])
+		})
+	}
+}
+
+func TestConverter_Run_EmbedBlocks(t *testing.T) {
+	notes := map[string]string{
+		"Blocks.md": note(
+			"## Heading",
+			"Paragraph ^para",
+			"",
+			"- one",
+			"- two ^item",
+			"  continued",
+			"  - nested",
+			"- three",
+			"  more ^cont",
+			"",
+			"- a",
+			"- b",
+			"",
+			"^list",
+			"",
+			"### Marked heading ^head",
+			"After",
+		),
+	}
+
+	for id, expect := range map[string]string{
+		"para": `Paragraph <span id="para"></span>`,
+		"item": "- two <span id=\"item\"></span>\n  continued\n  - nested",
+		"cont": "- three\n  more <span id=\"cont\"></span>",
+		"list": "- a\n- b",
+		"head": `### Marked heading <span id="head"></span>`,
+	} {
+		notes["Embedding.md"] = note("![[Blocks#^" + id + "]]")
+		pages := convertVault(t, notes, nil)
+		assert.Equal(t, expect, pages["embedding.md"], id)
+	}
+}
+
+func TestConverter_Run_EmbedNotesCircular(t *testing.T) {
+	pages := convertVault(t, map[string]string{
+		"One.md":   note("One embeds ![[Two]]"),
+		"Two.md":   note("Two embeds ![[One]]"),
+		"Three.md": note("# Self", "", "Three embeds ![[#Self]] and ![[Three]]"),
+	}, nil)
+
+	assert.Equal(t, "One embeds\n\nTwo embeds [One](/sub-path/one/)", pages["one.md"])
+	assert.Equal(t, "Two embeds\n\nOne embeds [Two](/sub-path/two/)", pages["two.md"])
+	assert.Equal(t, "# Self\n\nThree embeds\n\n# Self\n\nThree embeds [Self](/sub-path/three/#self) and [Three](/sub-path/three/)"+
+		"\n\nand [Three](/sub-path/three/)", pages["three.md"])
+}
+
+func TestConverter_Run_EmbedNotesDepth(t *testing.T) {
+	pages := convertVault(t, map[string]string{
+		"One.md":   note("One ![[Two]]"),
+		"Two.md":   note("Two ![[Three]]"),
+		"Three.md": note("Three"),
+	}, func(converter *omh.Converter) {
+		converter.EmbedDepth = 1
+	})
+
+	assert.Equal(t, "One\n\nTwo [Three](/sub-path/three/)", pages["one.md"])
+	assert.Equal(t, "Two\n\nThree", pages["two.md"])
+}
+
+func TestConverter_Run_EmbedFiles(t *testing.T) {
+	pages := convertVault(t, map[string]string{
+		"Files/Image.PNG":   "",
+		"Files/Audio.mp3":   "",
+		"Files/Video.mp4":   "",
+		"Files/Spec.pdf":    "",
+		"Files/Archive.zip": "",
+		"Files/Data.csv":    "",
+		"Embedding.md": note(
+			"![[Image.PNG]]",
+			"![[Audio.mp3]]",
+			"![[Video.mp4]]",
+			"![[Spec.pdf]]",
+			"![[Archive.zip]]",
+			"![[Data.csv]]",
+		),
+	}, func(converter *omh.Converter) {
+		converter.EmbedKinds = map[string]omh.EmbedKind{".csv": omh.EmbedPDF}
+	})
+
+	assert.Equal(t, `![Image.PNG](/sub-path/files/image.PNG)
+<audio controls src="/sub-path/files/audio.mp3" title="Audio.mp3"></audio>
+<video controls src="/sub-path/files/video.mp4" title="Video.mp4"></video>
+<iframe src="/sub-path/files/spec.pdf" title="Spec.pdf"></iframe>
+[Archive.zip](/sub-path/files/archive.zip)
+<iframe src="/sub-path/files/data.csv" title="Data.csv"></iframe>`, pages["embedding.md"])
+}
+
+func TestConverter_Run_EmbedSizedImages(t *testing.T) {
+	notes := map[string]string{
+		"Image.png":    "",
+		"Embedding.md": note("![[Image.png|400]] and ![[Image.png|400x300]]"),
+	}
+
+	pages := convertVault(t, notes, nil)
+	assert.Equal(t, `<img src="/sub-path/image.png" alt="Image.png" width="400"> and `+
+		`<img src="/sub-path/image.png" alt="Image.png" width="400" height="300">`, pages["embedding.md"])
+
+	pages = convertVault(t, notes, func(converter *omh.Converter) {
+		converter.SizedImages = omh.SizedImageFigure
+	})
+	assert.Equal(t, `{{< figure src="/sub-path/image.png" alt="Image.png" width="400" >}} and `+
+		`{{< figure src="/sub-path/image.png" alt="Image.png" width="400" height="300" >}}`, pages["embedding.md"])
+}
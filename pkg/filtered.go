@@ -0,0 +1,44 @@
+package omh
+
+import (
+	"fmt"
+	"html"
+	"path"
+)
+
+// FilteredLinkPolicy is how links to notes, that were rejected by the filter, are rendered in Hugo
+type FilteredLinkPolicy string
+
+const (
+	// FilteredLinkText renders links to filtered notes as plain text, using the title of the link
+	FilteredLinkText FilteredLinkPolicy = "text"
+
+	// FilteredLinkPrivate renders links to filtered notes as HTML link with the `private` CSS class, like
+	// `<a class="private" href="/sub-path/some-note/">Some Note</a>`, so that the theme can style them
+	FilteredLinkPrivate FilteredLinkPolicy = "private"
+
+	// FilteredLinkPlaceholder replaces links to filtered notes with FilteredPlaceholder, so that their titles are
+	// never published
+	FilteredLinkPlaceholder FilteredLinkPolicy = "placeholder"
+
+	// FilteredLinkFail fails the conversion, before anything is written, if any note links to filtered notes
+	FilteredLinkFail FilteredLinkPolicy = "fail"
+)
+
+// DefaultFilteredPlaceholder is the default text, that replaces links to filtered notes with FilteredLinkPlaceholder
+const DefaultFilteredPlaceholder = "private note"
+
+// filteredLink renders the link with title to the filtered note located at target, as configured in FilteredLinks
+func (c Converter) filteredLink(title, target string) string {
+	switch c.FilteredLinks {
+	case FilteredLinkPrivate:
+		return fmt.Sprintf(`<a class="private" href="%s">%s</a>`, html.EscapeString("/"+path.Join(c.SubPath, target)+"/"), html.EscapeString(title))
+	case FilteredLinkPlaceholder:
+		if c.FilteredPlaceholder == "" {
+			return DefaultFilteredPlaceholder
+		}
+		return c.FilteredPlaceholder
+	default:
+		return title
+	}
+}
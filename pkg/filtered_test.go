@@ -0,0 +1,170 @@
+package omh_test
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/iancoleman/strcase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	omh "github.com/ukautz/obsidian-meets-hugo/pkg"
+)
+
+func TestConverter_Run_FilteredLinks(t *testing.T) {
+	notes := map[string]string{
+		"Public.md": note(
+			"See [[Secret Plans|the plans]] and [[Secret Plans#Budget]].",
+			"Also [the details](Secret%20Plans.md) and [[Elsewhere]].",
+		),
+		"Elsewhere.md":    note("Nothing to see"),
+		"Secret Plans.md": "---\ntags: [private]\n---\n\n# Budget",
+	}
+
+	for name, expect := range map[omh.FilteredLinkPolicy]string{
+		"": "See the plans and Secret Plans > Budget.\n" +
+			"Also the details and [Elsewhere](/sub-path/elsewhere/).",
+		omh.FilteredLinkText: "See the plans and Secret Plans > Budget.\n" +
+			"Also the details and [Elsewhere](/sub-path/elsewhere/).",
+		omh.FilteredLinkPrivate: `See <a class="private" href="/sub-path/secret-plans/">the plans</a> and ` +
+			`<a class="private" href="/sub-path/secret-plans/">Secret Plans &gt; Budget</a>.` + "\n" +
+			`Also <a class="private" href="/sub-path/secret-plans/">the details</a> and [Elsewhere](/sub-path/elsewhere/).`,
+		omh.FilteredLinkPlaceholder: "See private note and private note.\n" +
+			"Also private note and [Elsewhere](/sub-path/elsewhere/).",
+	} {
+		policy := name
+		t.Run(string(policy), func(t *testing.T) {
+			pages, err := convertFilteredVault(t, notes, func(converter *omh.Converter) {
+				converter.FilteredLinks = policy
+			})
+			require.NoError(t, err)
+			assert.Equal(t, expect, pages["public.md"])
+			assert.NotContains(t, pages, "secret-plans.md")
+		})
+	}
+
+	t.Run("custom placeholder", func(t *testing.T) {
+		pages, err := convertFilteredVault(t, notes, func(converter *omh.Converter) {
+			converter.FilteredLinks = omh.FilteredLinkPlaceholder
+			converter.FilteredPlaceholder = "[redacted]"
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "See [redacted] and [redacted].\nAlso [redacted] and [Elsewhere](/sub-path/elsewhere/).", pages["public.md"])
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		failing := map[string]string{"Diagram.png": "png", "Another.md": note("Also [[Secret Plans]]")}
+		for file, content := range notes {
+			failing[file] = content
+		}
+		root, err := omh.LoadObsidianDirectory(writeVault(t, failing), func(note omh.ObsidianNote) bool {
+			return note.String("tags") != "[private]"
+		}, true)
+		require.NoError(t, err)
+
+		output := t.TempDir()
+		converter := omh.Converter{
+			ConvertName:   strcase.ToKebab,
+			ObsidianRoot:  root,
+			HugoRoot:      output,
+			SubPath:       "sub-path",
+			FilteredLinks: omh.FilteredLinkFail,
+		}
+		err = converter.Run()
+		require.Error(t, err)
+		assert.Equal(t, "found 4 problems in vault:\n"+
+			"  Another.md:5: link to filtered note [[Secret Plans]]\n"+
+			"  Public.md:5: link to filtered note [[Secret Plans|the plans]]\n"+
+			"  Public.md:5: link to filtered note [[Secret Plans#Budget]]\n"+
+			"  Public.md:6: link to filtered note [the details](Secret%20Plans.md)", err.Error())
+
+		// nothing is written, if any note links to filtered notes
+		written, err := ioutil.ReadDir(output)
+		require.NoError(t, err)
+		assert.Empty(t, written)
+	})
+}
+
+// convertFilteredVault is like convertVault, but excludes notes tagged `private` and returns the conversion error
+func convertFilteredVault(t *testing.T, notes map[string]string, configure func(converter *omh.Converter)) (map[string]string, error) {
+	pages, err := runVault(t, notes, func(note omh.ObsidianNote) bool {
+		for _, tag := range note.Strings("tags") {
+			if tag == "private" {
+				return false
+			}
+		}
+		return true
+	}, configure)
+	for file, page := range pages {
+		pages[file] = page[strings.Index(page, "\n---\n")+7:]
+	}
+	return pages, err
+}
+
+func TestConverter_Run_FilteredLinks_References(t *testing.T) {
+	notes := map[string]string{
+		"Public.md": note(
+			"See [the plans][1], [Secret Plans][] and [secret  plans].",
+			"Keep [Elsewhere][2] and [brackets].",
+			"",
+			"[1]: Secret%20Plans.md",
+			"[Secret Plans]: <Secret Plans.md>",
+			"[2]: Elsewhere.md",
+		),
+		"Elsewhere.md":    note("Nothing to see"),
+		"Secret Plans.md": "---\ntags: [private]\n---\n\n# Budget",
+	}
+
+	pages, err := convertFilteredVault(t, notes, func(converter *omh.Converter) {
+		converter.FilteredLinks = omh.FilteredLinkPlaceholder
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "See private note, private note and private note.\n"+
+		"Keep [Elsewhere][2] and [brackets].\n\n\n\n[2]: /sub-path/elsewhere/", pages["public.md"])
+	assert.NotContains(t, pages["public.md"], "Secret")
+}
+
+func TestConverter_Run_FilteredLinks_Backlinks(t *testing.T) {
+	notes := map[string]string{
+		"Public.md":       note("See [[Secret Plans]] and [[Beta]] too"),
+		"Beta.md":         note("Beta"),
+		"Secret Plans.md": "---\ntags: [private]\n---\n\n# Budget",
+	}
+
+	for policy, expect := range map[omh.FilteredLinkPolicy]string{
+		omh.FilteredLinkText:        "excerpt: See Secret Plans and Beta too",
+		omh.FilteredLinkPrivate:     "excerpt: See Secret Plans and Beta too",
+		omh.FilteredLinkPlaceholder: "excerpt: See private note and Beta too",
+	} {
+		policy, expect := policy, expect
+		t.Run(string(policy), func(t *testing.T) {
+			pages, err := runVault(t, notes, func(note omh.ObsidianNote) bool {
+				return note.String("tags") != "[private]"
+			}, func(converter *omh.Converter) {
+				converter.FilteredLinks = policy
+				converter.BacklinksKey = "backlinks"
+			})
+			require.NoError(t, err)
+			assert.Contains(t, pages["beta.md"], expect)
+			if policy == omh.FilteredLinkPlaceholder {
+				assert.NotContains(t, pages["beta.md"], "Secret")
+			}
+		})
+	}
+
+	t.Run("markdown and references", func(t *testing.T) {
+		notes := map[string]string{
+			"Public.md":       note("Also [the plans](Secret%20Plans.md), [Secret Plans][1] and [[Beta]]", "", "[1]: Secret%20Plans.md"),
+			"Beta.md":         note("Beta"),
+			"Secret Plans.md": "---\ntags: [private]\n---\n\n# Budget",
+		}
+		pages, err := runVault(t, notes, func(note omh.ObsidianNote) bool {
+			return note.String("tags") != "[private]"
+		}, func(converter *omh.Converter) {
+			converter.FilteredLinks = omh.FilteredLinkPlaceholder
+			converter.BacklinksKey = "backlinks"
+		})
+		require.NoError(t, err)
+		assert.Contains(t, pages["beta.md"], "excerpt: Also private note, private note and Beta")
+	})
+}
@@ -62,6 +62,8 @@ func (fm FrontMatter) Strings(key string) []string {
 	return nil
 }
 
+// ParseFrontMatterMarkdown returns the front matter, which must start in the first line, and the (trimmed) content of
+// the Markdown document. If the document has no or empty front matter, ErrNoFrontMatter is returned with the content.
 func ParseFrontMatterMarkdown(content []byte) (FrontMatter, string, error) {
 	metaLines := make([]string, 0)
 	bodyLines := make([]string, 0)
@@ -70,7 +72,9 @@ func ParseFrontMatterMarkdown(content []byte) (FrontMatter, string, error) {
 	scanner := bufio.NewScanner(bytes.NewReader(content))
 	for scanner.Scan() {
 		line := scanner.Text()
-		if state < 2 && line == "---" {
+		if state == 0 && line != "---" {
+			return nil, strings.TrimSpace(string(content)), ErrNoFrontMatter
+		} else if state < 2 && line == "---" {
 			state++
 			continue
 		}
@@ -81,7 +85,7 @@ func ParseFrontMatterMarkdown(content []byte) (FrontMatter, string, error) {
 		}
 	}
 	if len(metaLines) == 0 {
-		return nil, "", ErrNoFrontMatter
+		return nil, strings.TrimSpace(strings.Join(bodyLines, "\n")), ErrNoFrontMatter
 	}
 
 	meta := make(map[string]interface{})
@@ -93,6 +97,31 @@ func ParseFrontMatterMarkdown(content []byte) (FrontMatter, string, error) {
 	return FrontMatter(meta), strings.TrimSpace(strings.Join(bodyLines, "\n")), nil
 }
 
+// frontMatterLines returns the line numbers of the top-level keys in the front matter and the line number, that the
+// (trimmed) content after the front matter starts at, as parsed by ParseFrontMatterMarkdown
+func frontMatterLines(content []byte) (map[string]int, int) {
+	keys := make(map[string]int)
+	state, line := 0, 0
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		text := scanner.Text()
+		line++
+		if state == 0 && text != "---" {
+			state = 2
+		}
+		if state < 2 && text == "---" {
+			state++
+		} else if state == 1 && text != "" && text[0] != ' ' && text[0] != '\t' && text[0] != '-' && text[0] != '#' {
+			if i := strings.Index(text, ":"); i > 0 {
+				keys[strings.Trim(text[0:i], `"'`)] = line
+			}
+		} else if state == 2 && strings.TrimSpace(text) != "" {
+			return keys, line
+		}
+	}
+	return keys, 1
+}
+
 func init() {
 	frontMatter = front.NewMatter()
 	frontMatter.Handle("---", front.YAMLHandler)
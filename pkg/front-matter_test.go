@@ -87,3 +87,23 @@ func TestParseFrontMatterMarkdown_FrontMatterNotCode(t *testing.T) {
 	}, fm)
 	assert.Equal(t, rawBody, body)
 }
+
+func TestParseFrontMatterMarkdown_NoFrontMatter(t *testing.T) {
+	for name, test := range map[string]struct {
+		raw  string
+		body string
+	}{
+		"plain":              {raw: "Just text\n", body: "Just text"},
+		"horizontal rule":    {raw: "Intro\n\n---\n\nMore: text\n", body: "Intro\n\n---\n\nMore: text"},
+		"leading blank":      {raw: "\n---\nfoo: 1\n---\nBody", body: "---\nfoo: 1\n---\nBody"},
+		"empty front matter": {raw: "---\n---\n\nBody\n", body: "Body"},
+	} {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			fm, body, err := omh.ParseFrontMatterMarkdown([]byte(test.raw))
+			assert.ErrorIs(t, err, omh.ErrNoFrontMatter)
+			assert.Nil(t, fm)
+			assert.Equal(t, test.body, body)
+		})
+	}
+}
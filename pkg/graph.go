@@ -0,0 +1,128 @@
+package omh
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"strings"
+)
+
+// GraphEdgeKind is the kind of relation between two notes in the Graph
+type GraphEdgeKind string
+
+const (
+	// GraphLink is a link from the source note to the target note
+	GraphLink GraphEdgeKind = "link"
+
+	// GraphEmbed is an embedding of the target note in the source note
+	GraphEmbed GraphEdgeKind = "embed"
+
+	// GraphTag is a tag of the source note, with the node of the tag as target
+	GraphTag GraphEdgeKind = "tag"
+)
+
+// GraphNodeKind is the kind of a node in the Graph
+type GraphNodeKind string
+
+const (
+	// GraphNoteNode is a converted note
+	GraphNoteNode GraphNodeKind = "note"
+
+	// GraphTagNode is a tag of any of the converted notes, with the ID `#tag`
+	GraphTagNode GraphNodeKind = "tag"
+)
+
+// Graph contains all converted notes and the relations between them, so that graph views can be rendered in Hugo
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is a converted note or a tag in the Graph
+type GraphNode struct {
+
+	// ID is the Hugo compatible web link of the note, relative to the sub-path, or the tag with leading `#`
+	ID string `json:"id"`
+
+	// Kind is whether the node is a note or a tag
+	Kind GraphNodeKind `json:"kind"`
+
+	// Title is the title of the note, or the tag
+	Title string `json:"title"`
+
+	// URL is the absolute URL of the Hugo page of the note, or of the taxonomy term page of the tag
+	URL string `json:"url"`
+
+	// Tags are the tags of the note
+	Tags []string `json:"tags,omitempty"`
+
+	// Folder is the directory of the note in the vault
+	Folder string `json:"folder,omitempty"`
+}
+
+// GraphEdge is a relation between two notes in the Graph
+type GraphEdge struct {
+	Source string        `json:"source"`
+	Target string        `json:"target"`
+	Kind   GraphEdgeKind `json:"kind"`
+	Tag    string        `json:"tag,omitempty"`
+}
+
+// graph returns the graph of all notes, which are about to be converted, their tags and the relations between them
+func (c Converter) graph() Graph {
+	graph := Graph{
+		Nodes: make([]GraphNode, 0),
+		Edges: make([]GraphEdge, 0),
+	}
+
+	seen := make(map[GraphEdge]bool)
+	tagNodes := make([]GraphNode, 0)
+	tagged := make(map[string]bool)
+	c.ObsidianRoot.walkNotes(c.ConvertName, func(note ObsidianNote, vaultPath, target string) {
+		folder := path.Dir(vaultPath)
+		if folder == "." {
+			folder = ""
+		}
+		noteTags := note.Tags()
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			ID:     target,
+			Kind:   GraphNoteNode,
+			Title:  note.Title,
+			URL:    "/" + c.SubPath + "/" + target,
+			Tags:   noteTags,
+			Folder: folder,
+		})
+
+		for _, link := range c.scanLinks(note, target) {
+			edge := GraphEdge{Source: target, Target: link.Target, Kind: GraphLink}
+			if link.Embed {
+				edge.Kind = GraphEmbed
+			}
+			if seen[edge] || link.Filtered || edge.Source == edge.Target || !strings.HasSuffix(edge.Target, "/") {
+				continue
+			}
+			seen[edge] = true
+			graph.Edges = append(graph.Edges, edge)
+		}
+
+		// notes are connected to the nodes of their tags, instead of to each other, so that the graph grows linearly
+		for _, tag := range noteTags {
+			if !tagged[tag] {
+				tagged[tag] = true
+				tagNodes = append(tagNodes, GraphNode{ID: "#" + tag, Kind: GraphTagNode, Title: tag, URL: c.tagURL(tag)})
+			}
+			graph.Edges = append(graph.Edges, GraphEdge{Source: target, Target: "#" + tag, Kind: GraphTag, Tag: tag})
+		}
+	})
+	graph.Nodes = append(graph.Nodes, tagNodes...)
+
+	return graph
+}
+
+func (c Converter) writeGraph(file string) error {
+	raw, err := json.Marshal(c.graph())
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, raw, 0644)
+}
@@ -0,0 +1,62 @@
+package omh_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/iancoleman/strcase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	omh "github.com/ukautz/obsidian-meets-hugo/pkg"
+)
+
+func TestConverter_Run_Graph(t *testing.T) {
+	source := writeVault(t, map[string]string{
+		"One.md":       "---\ntags: [public, a]\n---\n\n[[Two]], [[Two#Heading]], ![[Sub/Three]] and [[One]]",
+		"Two.md":       "---\ntags: [public, a]\n---\n\n[Three](Sub/Three.md) and [[Private]] and [[Image.png]]",
+		"Sub/Three.md": "---\ntags: [public]\n---\n\nThree",
+		"Private.md":   "---\ntags: [private]\n---\n\n[[One]]",
+		"Image.png":    "",
+	})
+	root, err := omh.LoadObsidianDirectory(source, func(note omh.ObsidianNote) bool {
+		return note.Title != "Private"
+	}, true)
+	require.NoError(t, err)
+
+	output := t.TempDir()
+	converter := omh.Converter{
+		ConvertName:  strcase.ToKebab,
+		ObsidianRoot: root,
+		HugoRoot:     output,
+		SubPath:      "sub-path",
+		GraphFile:    "graph.json",
+	}
+	require.NoError(t, converter.Run())
+
+	raw, err := ioutil.ReadFile(filepath.Join(output, "static", "sub-path", "graph.json"))
+	require.NoError(t, err)
+	var graph omh.Graph
+	require.NoError(t, json.Unmarshal(raw, &graph))
+
+	assert.Equal(t, omh.Graph{
+		Nodes: []omh.GraphNode{
+			{ID: "one/", Kind: omh.GraphNoteNode, Title: "One", URL: "/sub-path/one/", Tags: []string{"public", "a"}},
+			{ID: "two/", Kind: omh.GraphNoteNode, Title: "Two", URL: "/sub-path/two/", Tags: []string{"public", "a"}},
+			{ID: "sub/three/", Kind: omh.GraphNoteNode, Title: "Three", URL: "/sub-path/sub/three/", Tags: []string{"public"}, Folder: "Sub"},
+			{ID: "#public", Kind: omh.GraphTagNode, Title: "public", URL: "/tags/public/"},
+			{ID: "#a", Kind: omh.GraphTagNode, Title: "a", URL: "/tags/a/"},
+		},
+		Edges: []omh.GraphEdge{
+			{Source: "one/", Target: "two/", Kind: omh.GraphLink},
+			{Source: "one/", Target: "sub/three/", Kind: omh.GraphEmbed},
+			{Source: "one/", Target: "#public", Kind: omh.GraphTag, Tag: "public"},
+			{Source: "one/", Target: "#a", Kind: omh.GraphTag, Tag: "a"},
+			{Source: "two/", Target: "sub/three/", Kind: omh.GraphLink},
+			{Source: "two/", Target: "#public", Kind: omh.GraphTag, Tag: "public"},
+			{Source: "two/", Target: "#a", Kind: omh.GraphTag, Tag: "a"},
+			{Source: "sub/three/", Target: "#public", Kind: omh.GraphTag, Tag: "public"},
+		},
+	}, graph)
+}
@@ -0,0 +1,138 @@
+package omh
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFile is the name of the files in the vault, that contain gitignore-style patterns of notes, files and
+// directories, that are not loaded. Patterns apply to the directory of the file and it's sub-directories.
+const IgnoreFile = ".omhignore"
+
+// ignorePattern is a gitignore-style pattern, like `Templates/`, `*.excalidraw.md` or `!Daily/Important.md`
+type ignorePattern struct {
+
+	// base is the path of the directory in the vault, that the pattern is relative to
+	base string
+
+	// segments are the slash separated parts of the pattern, with `**` matching any number of directories
+	segments []string
+
+	// negate is whether matching paths are included again
+	negate bool
+
+	// directory is whether the pattern only matches directories
+	directory bool
+}
+
+// parseIgnorePattern parses a line of an ignore file in the directory base of the vault, or returns false if the line
+// is blank or a comment
+func parseIgnorePattern(base, line string) (ignorePattern, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false
+	}
+
+	pattern := ignorePattern{base: base}
+	if strings.HasPrefix(line, "!") {
+		pattern.negate, line = true, line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		pattern.directory, line = true, strings.TrimRight(line, "/")
+	}
+
+	// patterns without slash match at any depth, others are relative to the base
+	if !strings.Contains(line, "/") {
+		line = "**/" + line
+	}
+	line = strings.TrimPrefix(line, "/")
+	if line == "" || line == "**/" {
+		return ignorePattern{}, false
+	}
+	pattern.segments = strings.Split(line, "/")
+
+	return pattern, true
+}
+
+// parseIgnorePatterns parses all lines of an ignore file in the directory base of the vault
+func parseIgnorePatterns(base string, content []byte) []ignorePattern {
+	patterns := make([]ignorePattern, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if pattern, ok := parseIgnorePattern(base, scanner.Text()); ok {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// loadIgnoreFile returns the patterns of the ignore file in the directory at dir, located at base in the vault
+func loadIgnoreFile(dir, base string) ([]ignorePattern, error) {
+	content, err := ioutil.ReadFile(filepath.Join(dir, IgnoreFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return parseIgnorePatterns(base, content), nil
+}
+
+// matches returns whether the pattern matches the path in the vault
+func (pattern ignorePattern) matches(vaultPath string, directory bool) bool {
+	if pattern.directory && !directory {
+		return false
+	}
+
+	rel := vaultPath
+	if pattern.base != "" {
+		if !strings.HasPrefix(vaultPath, pattern.base+"/") {
+			return false
+		}
+		rel = vaultPath[len(pattern.base)+1:]
+	}
+
+	return matchSegments(pattern.segments, strings.Split(rel, "/"))
+}
+
+// matchSegments returns whether the pattern segments match the path segments, with `**` matching any number of
+// segments
+func matchSegments(patterns, segments []string) bool {
+	if len(patterns) == 0 {
+		return len(segments) == 0
+	}
+
+	if patterns[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(patterns[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+	if matched, err := path.Match(patterns[0], segments[0]); err != nil || !matched {
+		return false
+	}
+	return matchSegments(patterns[1:], segments[1:])
+}
+
+// ignored returns whether the path in the vault is ignored by the patterns, where later patterns take precedence
+func ignored(patterns []ignorePattern, vaultPath string, directory bool) bool {
+	ignore := false
+	for _, pattern := range patterns {
+		if pattern.matches(vaultPath, directory) {
+			ignore = !pattern.negate
+		}
+	}
+	return ignore
+}
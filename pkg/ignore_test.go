@@ -0,0 +1,58 @@
+package omh_test
+
+import (
+	"path"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	omh "github.com/ukautz/obsidian-meets-hugo/pkg"
+)
+
+func TestObsidianLoader_Ignore(t *testing.T) {
+	source := writeVault(t, map[string]string{
+		".omhignore":                "# vault wide\nTemplates/\n*.excalidraw.md\n/Daily\n!Keep.excalidraw.md\n",
+		"Index.md":                  note("Index"),
+		"Drawing.excalidraw.md":     note("Drawing"),
+		"Keep.excalidraw.md":        note("Keep"),
+		"Templates/Template.md":     note("Template"),
+		"Daily/2023-01-01.md":       note("Daily"),
+		"Projects/Daily/Standup.md": note("Standup"),
+		"Projects/.omhignore":       "**/drafts/**\nsecret.png\n",
+		"Projects/Alpha.md":         note("Alpha"),
+		"Projects/secret.png":       "png",
+		"Projects/drafts/Draft.md":  note("Draft"),
+		"Projects/Sub/drafts/x.md":  note("Draft"),
+		"Archive/Old.md":            note("Old"),
+		"Archive/diagram.png":       "png",
+		"secret.png":                "png",
+	})
+
+	directory, err := omh.ObsidianLoader{Recurse: true, Ignore: []string{"Archive/"}}.Load(source)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"Index.md",
+		"Keep.excalidraw.md",
+		"Projects/Alpha.md",
+		"Projects/Daily/Standup.md",
+		"secret.png",
+	}, loadedPaths(directory, ""))
+}
+
+// loadedPaths returns the sorted paths of all notes and files in the directory
+func loadedPaths(directory omh.ObsidianDirectory, prefix string) []string {
+	paths := make([]string, 0)
+	for _, note := range directory.Notes {
+		paths = append(paths, path.Join(prefix, note.Title+".md"))
+	}
+	for _, file := range directory.Files {
+		paths = append(paths, path.Join(prefix, file))
+	}
+	for _, sub := range directory.Childs {
+		paths = append(paths, loadedPaths(sub, path.Join(prefix, sub.Name))...)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,117 @@
+package omh
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LinkMode is how links to notes are rendered in Hugo
+type LinkMode string
+
+const (
+	// LinkURL renders links to notes as absolute URL, like `/sub-path/some-note/`
+	LinkURL LinkMode = "url"
+
+	// LinkRef renders links to notes with the Hugo `ref` shortcode, like `{{< ref "sub-path/some-note.md" >}}`
+	LinkRef LinkMode = "ref"
+
+	// LinkRelRef renders links to notes with the Hugo `relref` shortcode, like `{{< relref "sub-path/some-note.md" >}}`
+	LinkRelRef LinkMode = "relref"
+)
+
+// obsidianSize matches the dimensions of embedded images, like `![[image.png|400]]` or `![[image.png|400x300]]`
+var obsidianSize = regexp.MustCompile(`^\s*(\d+)(?:x(\d+))?\s*$`)
+
+// ObsidianLink is an internal link in Obsidian notation, like `[[Some Note#Some Heading|Some Title]]` or `[[Some Note^abc123]]`
+type ObsidianLink struct {
+
+	// Embed is whether the link target is embedded (`![[...]]`), instead of linked
+	Embed bool
+
+	// Target is the name of the linked note or file, empty if the link points to a heading in the same note
+	Target string
+
+	// Heading is the optional heading within the linked note
+	Heading string
+
+	// Block is the optional ID of a referenced block within the linked note
+	Block string
+
+	// Title is the optional alternative title of the link
+	Title string
+
+	// Width is the optional width of an embedded image
+	Width int
+
+	// Height is the optional height of an embedded image
+	Height int
+}
+
+// ParseObsidianLink parses an internal link from it's Obsidian notation (`[[...]]` or `![[...]]`)
+func ParseObsidianLink(raw string) ObsidianLink {
+	var link ObsidianLink
+	if strings.HasPrefix(raw, "!") {
+		link.Embed = true
+		raw = raw[1:]
+	}
+	raw = strings.TrimPrefix(raw, "[[")
+	raw = strings.TrimSuffix(raw, "]]")
+
+	if i := strings.Index(raw, "|"); i > -1 {
+		raw, link.Title = raw[0:i], raw[i+1:]
+	}
+
+	// embeds use the title for dimensions instead
+	if match := obsidianSize.FindStringSubmatch(link.Title); link.Embed && match != nil {
+		link.Width, _ = strconv.Atoi(match[1])
+		link.Height, _ = strconv.Atoi(match[2])
+		link.Title = ""
+	}
+
+	// nested headings, like `[[Note#Heading#Sub Heading]]`, point to the last heading
+	if i := strings.Index(raw, "#"); i > -1 {
+		raw, link.Heading = raw[0:i], raw[strings.LastIndex(raw, "#")+1:]
+	}
+
+	// block references are either `[[Note#^abc123]]` or `[[Note^abc123]]`
+	if strings.HasPrefix(link.Heading, "^") {
+		link.Block, link.Heading = link.Heading[1:], ""
+	} else if i := strings.Index(raw, "^"); i > -1 {
+		raw, link.Block = raw[0:i], raw[i+1:]
+	}
+	link.Target = strings.TrimSpace(raw)
+	link.Heading = strings.TrimSpace(link.Heading)
+	link.Block = strings.TrimSpace(link.Block)
+
+	return link
+}
+
+// DisplayTitle is the text that Obsidian would render for the link
+func (link ObsidianLink) DisplayTitle() string {
+	section := link.Heading
+	if link.Block != "" {
+		section = "^" + link.Block
+	}
+
+	switch {
+	case link.Title != "":
+		return link.Title
+	case section == "":
+		return link.Target
+	case link.Target == "":
+		return section
+	default:
+		return link.Target + " > " + section
+	}
+}
+
+// linkURL returns the link to the target note or file, with optional anchor, as configured in LinkMode. Files are
+// always linked by URL, because they are not Hugo pages.
+func (c Converter) linkURL(target, anchor string) string {
+	if (c.LinkMode == LinkRef || c.LinkMode == LinkRelRef) && strings.HasSuffix(target, "/") {
+		return fmt.Sprintf(`{{< %s "%s%s" >}}`, c.LinkMode, c.contentFile(target), anchor)
+	}
+	return fmt.Sprintf("/%s/%s%s", c.SubPath, target, anchor)
+}
@@ -0,0 +1,119 @@
+package omh_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	omh "github.com/ukautz/obsidian-meets-hugo/pkg"
+)
+
+func TestParseObsidianLink(t *testing.T) {
+	tests := map[string]struct {
+		from    string
+		expect  omh.ObsidianLink
+		display string
+	}{
+		"note": {
+			from:    "[[Some Note]]",
+			expect:  omh.ObsidianLink{Target: "Some Note"},
+			display: "Some Note",
+		},
+		"titled": {
+			from:    "[[Some Note|The Title]]",
+			expect:  omh.ObsidianLink{Target: "Some Note", Title: "The Title"},
+			display: "The Title",
+		},
+		"heading": {
+			from:    "[[Some Note#Setup Steps]]",
+			expect:  omh.ObsidianLink{Target: "Some Note", Heading: "Setup Steps"},
+			display: "Some Note > Setup Steps",
+		},
+		"nested heading": {
+			from:    "[[Some Note#Setup#Steps]]",
+			expect:  omh.ObsidianLink{Target: "Some Note", Heading: "Steps"},
+			display: "Some Note > Steps",
+		},
+		"titled heading": {
+			from:    "[[Some Note#Setup Steps|The Title]]",
+			expect:  omh.ObsidianLink{Target: "Some Note", Heading: "Setup Steps", Title: "The Title"},
+			display: "The Title",
+		},
+		"block": {
+			from:    "[[Some Note^abc123]]",
+			expect:  omh.ObsidianLink{Target: "Some Note", Block: "abc123"},
+			display: "Some Note > ^abc123",
+		},
+		"block as heading": {
+			from:    "[[Some Note#^abc123|The Title]]",
+			expect:  omh.ObsidianLink{Target: "Some Note", Block: "abc123", Title: "The Title"},
+			display: "The Title",
+		},
+		"local block": {
+			from:    "[[#^abc123]]",
+			expect:  omh.ObsidianLink{Block: "abc123"},
+			display: "^abc123",
+		},
+		"embed": {
+			from:    "![[Some Note]]",
+			expect:  omh.ObsidianLink{Embed: true, Target: "Some Note"},
+			display: "Some Note",
+		},
+		"embed with width": {
+			from:    "![[image.png|400]]",
+			expect:  omh.ObsidianLink{Embed: true, Target: "image.png", Width: 400},
+			display: "image.png",
+		},
+		"embed with width and height": {
+			from:    "![[image.png|400x300]]",
+			expect:  omh.ObsidianLink{Embed: true, Target: "image.png", Width: 400, Height: 300},
+			display: "image.png",
+		},
+		"link with numeric title": {
+			from:    "[[Some Note|400]]",
+			expect:  omh.ObsidianLink{Target: "Some Note", Title: "400"},
+			display: "400",
+		},
+		"local heading": {
+			from:    "[[#Local Heading]]",
+			expect:  omh.ObsidianLink{Heading: "Local Heading"},
+			display: "Local Heading",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			link := omh.ParseObsidianLink(test.from)
+			assert.Equal(t, test.expect, link)
+			assert.Equal(t, test.display, link.DisplayTitle())
+		})
+	}
+}
+
+func TestConverter_Run_LinkModes(t *testing.T) {
+	notes := map[string]string{
+		"Sub/Target.md": note("# Heading"),
+		"Image.png":     "",
+		"Source.md":     note("[[Target]], [[Target#Heading]], [md](Sub/Target.md) and ![[Image.png]]"),
+	}
+
+	tests := map[omh.LinkMode]string{
+		omh.LinkURL: "[Target](/sub-path/sub/target/), [Target > Heading](/sub-path/sub/target/#heading), " +
+			"[md](/sub-path/sub/target/) and ![Image.png](/sub-path/image.png)",
+		omh.LinkRef: `[Target]({{< ref "sub-path/sub/target.md" >}}), ` +
+			`[Target > Heading]({{< ref "sub-path/sub/target.md#heading" >}}), ` +
+			`[md]({{< ref "sub-path/sub/target.md" >}}) and ![Image.png](/sub-path/image.png)`,
+		omh.LinkRelRef: `[Target]({{< relref "sub-path/sub/target.md" >}}), ` +
+			`[Target > Heading]({{< relref "sub-path/sub/target.md#heading" >}}), ` +
+			`[md]({{< relref "sub-path/sub/target.md" >}}) and ![Image.png](/sub-path/image.png)`,
+	}
+
+	for mode, expect := range tests {
+		mode, expect := mode, expect
+		t.Run(string(mode), func(t *testing.T) {
+			pages := convertVault(t, notes, func(converter *omh.Converter) {
+				converter.LinkMode = mode
+			})
+			assert.Equal(t, expect, pages["source.md"])
+		})
+	}
+}
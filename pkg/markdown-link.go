@@ -0,0 +1,238 @@
+package omh
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// markdownLink matches inline Markdown links and images, like `[title](Some%20Note.md "optional title")`
+	markdownLink = regexp.MustCompile(`(!?\[(?:[^\[\]]|\[[^\[\]]*\])*\]\(\s*)(<[^<>\n]*>|[^\s()<>]+)((?:\s+(?:"[^"\n]*"|'[^'\n]*'))?\s*\))`)
+
+	// markdownReference matches reference link definitions, like `[label]: Some%20Note.md "optional title"`, but not
+	// footnote definitions, like `[^1]: Some text`
+	markdownReference = regexp.MustCompile(`(?m)^( {0,3}\[[^^\]\n][^\]\n]*\]:[ \t]*)(<[^<>\n]*>|\S+)(.*)$`)
+
+	// markdownReferenceUsage matches usages of reference links, like `[title][label]`, `[label][]` or `[label]`
+	markdownReferenceUsage = regexp.MustCompile(`!?\[((?:[^\[\]]|\[[^\[\]]*\])*)\](?:\[([^\[\]\n]*)\])?`)
+
+	// markdownAutolink matches autolinks and bare URLs, like `<https://example.com>` or `https://example.com`
+	markdownAutolink = regexp.MustCompile(`<[a-zA-Z][a-zA-Z0-9+.\-]*:[^\s<>]*>|\b[a-zA-Z][a-zA-Z0-9+.\-]*://[^\s<>]+`)
+
+	// urlScheme matches URLs with scheme, like `https://` or `mailto:`, that never point to the vault
+	urlScheme = regexp.MustCompile(`^(?:[a-zA-Z][a-zA-Z0-9+.\-]*:|//)`)
+)
+
+// convertMarkdownLinks rewrites the destinations of all Markdown links and images in the content, that point to notes
+// or files in the vault, into Hugo compatible web links. The content may contain inline code, which is never rewritten,
+// but can be part of the link text. Usages of the filtered reference definitions (see filteredReferences) are rendered
+// like links to filtered notes. See convertContent for the other parameters.
+func (c Converter) convertMarkdownLinks(note ObsidianNote, target, content string, embedded []string, filtered map[string]string) string {
+	rewrite := func(match []string, reference bool) string {
+		destination := match[2]
+		angled := strings.HasPrefix(destination, "<")
+		if angled {
+			destination = destination[1 : len(destination)-1]
+		}
+
+		rewritten, ok := c.rewriteMarkdownDestination(note, target, destination, len(embedded) > 1)
+		if ok && angled {
+			rewritten = "<" + rewritten + ">"
+		} else if !ok {
+
+			// links to filtered notes are replaced entirely, reference definitions are removed, after their usages
+			// were replaced
+			if filteredTarget, _, filtered := c.resolveFilteredMarkdownDestination(target, destination); filtered {
+				if reference {
+					return ""
+				}
+				title := strings.TrimSuffix(strings.TrimLeft(strings.TrimRight(match[1], " \t\n("), "!["), "]")
+				return c.filteredLink(title, filteredTarget)
+			}
+			return match[0]
+		}
+		return match[1] + rewritten + match[3]
+	}
+
+	content = replaceLinks(markdownLink, content, func(match []string) string {
+		return rewrite(match, false)
+	})
+	content = c.convertFilteredReferences(content, filtered)
+	return replaceLinks(markdownReference, content, func(match []string) string {
+		return rewrite(match, true)
+	})
+}
+
+// destinationSpans returns the start and end positions of all wikilinks, destinations of Markdown links, autolinks and
+// bare URLs in the prose
+func destinationSpans(prose string) [][]int {
+	spans := append(obsidianLink.FindAllStringIndex(prose, -1), markdownAutolink.FindAllStringIndex(prose, -1)...)
+	for _, loc := range markdownLink.FindAllStringSubmatchIndex(prose, -1) {
+		spans = append(spans, []int{loc[4], loc[5]})
+	}
+	return spans
+}
+
+// replaceLinks replaces all matches of the link expression, with the destination in the second group, in the text with
+// the result of fn, except for matches, that start, or whose destination starts, within inline code or comments
+func replaceLinks(re *regexp.Regexp, text string, fn func(match []string) string) string {
+	opaque := opaqueSpans(text)
+	var replaced strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringSubmatchIndex(text, -1) {
+		if inSpans(opaque, loc[0]) || inSpans(opaque, loc[4]) {
+			continue
+		}
+		match := make([]string, len(loc)/2)
+		for i := range match {
+			if loc[2*i] > -1 {
+				match[i] = text[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		replaced.WriteString(text[last:loc[0]])
+		replaced.WriteString(fn(match))
+		last = loc[1]
+	}
+	replaced.WriteString(text[last:])
+
+	return replaced.String()
+}
+
+// filteredReferences returns the Hugo compatible web links of the filtered notes, that reference link definitions in
+// the prose of the content of the note located at target point to, by normalized label
+func (c Converter) filteredReferences(target, content string) map[string]string {
+	filtered := make(map[string]string)
+	for _, segment := range scanMarkdown(content, false) {
+		if segment.Kind != markdownProse {
+			continue
+		}
+		for _, match := range markdownReference.FindAllStringSubmatch(segment.Text, -1) {
+			destination := strings.TrimSuffix(strings.TrimPrefix(match[2], "<"), ">")
+			if _, _, ok := c.resolveMarkdownDestination(target, destination); ok {
+				continue
+			}
+			if filteredTarget, _, ok := c.resolveFilteredMarkdownDestination(target, destination); ok {
+				label := strings.TrimSpace(match[1])
+				filtered[referenceLabel(label[1:strings.LastIndex(label, "]")])] = filteredTarget
+			}
+		}
+	}
+	return filtered
+}
+
+// convertFilteredReferences replaces the usages of the filtered reference definitions in the content, like links to
+// filtered notes
+func (c Converter) convertFilteredReferences(content string, filtered map[string]string) string {
+	if len(filtered) == 0 {
+		return content
+	}
+
+	var converted strings.Builder
+	last, opaque := 0, opaqueSpans(content)
+	for _, loc := range markdownReferenceUsage.FindAllStringSubmatchIndex(content, -1) {
+		if inSpans(opaque, loc[0]) {
+			continue
+		}
+		title := content[loc[2]:loc[3]]
+		label := title
+		if loc[4] > -1 && loc[5] > loc[4] {
+			label = content[loc[4]:loc[5]]
+		} else if loc[4] == -1 && loc[1] < len(content) && strings.ContainsRune("(:", rune(content[loc[1]])) {
+			// inline links and reference definitions are no usages
+			continue
+		}
+		filteredTarget, ok := filtered[referenceLabel(label)]
+		if !ok {
+			continue
+		}
+		converted.WriteString(content[last:loc[0]])
+		converted.WriteString(c.filteredLink(title, filteredTarget))
+		last = loc[1]
+	}
+	converted.WriteString(content[last:])
+
+	return converted.String()
+}
+
+// referenceLabel normalizes the label of a reference link, which is matched case-insensitive and ignoring whitespace
+func referenceLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// rewriteMarkdownDestination returns the Hugo compatible web link for the (URL encoded) destination of a Markdown link
+// in the note located at target, or false if the destination does not point to a note or file in the vault
+func (c Converter) rewriteMarkdownDestination(note ObsidianNote, target, destination string, embedded bool) (string, bool) {
+	resolved, anchor, ok := c.resolveMarkdownDestination(target, destination)
+	if !ok {
+		_, _, filtered := c.resolveFilteredMarkdownDestination(target, destination)
+		if !filtered && strings.HasSuffix(strings.SplitN(destination, "#", 2)[0], ".md") {
+			log.WithFields(log.Fields{
+				"link-target": destination,
+				"note":        note.Title,
+			}).Warn("missing target for note")
+		}
+		return "", false
+	}
+
+	// anchors within the same note stay relative, unless the note is embedded elsewhere
+	if resolved == "" {
+		if !embedded {
+			return anchor, true
+		}
+		resolved = target
+	}
+
+	return c.linkURL(resolved, anchor), true
+}
+
+// resolveMarkdownDestination returns the Hugo compatible web link and the anchor for the (URL encoded) destination of
+// a Markdown link in the note located at target, or false if the destination does not point to a note or file in the
+// vault. The returned link is empty, if the destination is an anchor in the same note.
+func (c Converter) resolveMarkdownDestination(target, destination string) (string, string, bool) {
+	return c.resolveMarkdownDestinationWith(c.resolveLink, target, destination)
+}
+
+// resolveFilteredMarkdownDestination is like resolveMarkdownDestination, but returns the (not converted) Hugo
+// compatible web link of the note, that was rejected by the filter
+func (c Converter) resolveFilteredMarkdownDestination(target, destination string) (string, string, bool) {
+	resolved, anchor, ok := c.resolveMarkdownDestinationWith(c.resolveFilteredLink, target, destination)
+	return resolved, anchor, ok && resolved != ""
+}
+
+func (c Converter) resolveMarkdownDestinationWith(resolve func(target, link string) (string, bool), target, destination string) (string, string, bool) {
+	if destination == "" || urlScheme.MatchString(destination) {
+		return "", "", false
+	}
+
+	decoded, err := url.PathUnescape(destination)
+	if err != nil {
+		return "", "", false
+	}
+
+	link, anchor := decoded, ""
+	if i := strings.Index(decoded, "#"); i > -1 {
+		link, anchor = decoded[0:i], decoded[i+1:]
+	}
+	if strings.HasPrefix(anchor, "^") {
+		anchor = "#" + anchor[1:]
+	} else if anchor != "" {
+		anchor = "#" + c.AnchorStyle.Anchor(anchor)
+	}
+	if link == "" {
+		return "", anchor, anchor != ""
+	}
+
+	// links are relative to the note, or relative to the vault
+	resolved, ok := "", false
+	if !strings.HasPrefix(link, "/") {
+		resolved, ok = resolve(target, "./"+link)
+	}
+	if !ok {
+		resolved, ok = resolve(target, link)
+	}
+
+	return resolved, anchor, ok
+}
@@ -0,0 +1,61 @@
+package omh_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	omh "github.com/ukautz/obsidian-meets-hugo/pkg"
+)
+
+func TestConverter_Run_MarkdownLinks(t *testing.T) {
+	pages := convertVault(t, map[string]string{
+		"Other Note.md":                    note("# Setup Steps"),
+		"Sub Directory/Additional Note.md": note("Additional ^abc"),
+		"Sub Directory/Image.png":          "",
+		"Sub Directory/Linking.md": note(
+			"[Relative](Additional%20Note.md) and [Vault](Sub%20Directory/Additional%20Note.md)",
+			"[Up](../Other%20Note.md#Setup%20Steps \"The Title\") and [Block](<Additional Note.md#^abc>)",
+			"![Image](Image.png) and ![Vault Image](/Sub%20Directory/Image.png)",
+			"[Local](#Some%20Heading) and [External](https://example.com/Other%20Note.md)",
+			"[Missing](Missing.md) and [Hugo](/about/)",
+			"",
+			"[reference]: Additional%20Note.md 'Reference Title'",
+			"[external]: https://example.com",
+		),
+	}, nil)
+
+	assert.Equal(t,
+		"[Relative](/sub-path/sub-directory/additional-note/) and [Vault](/sub-path/sub-directory/additional-note/)\n"+
+			"[Up](/sub-path/other-note/#setup-steps \"The Title\") and [Block](</sub-path/sub-directory/additional-note/#abc>)\n"+
+			"![Image](/sub-path/sub-directory/image.png) and ![Vault Image](/sub-path/sub-directory/image.png)\n"+
+			"[Local](#some-heading) and [External](https://example.com/Other%20Note.md)\n"+
+			"[Missing](Missing.md) and [Hugo](/about/)\n"+
+			"\n"+
+			"[reference]: /sub-path/sub-directory/additional-note/ 'Reference Title'\n"+
+			"[external]: https://example.com",
+		pages["sub-directory/linking.md"])
+}
+
+func TestConverter_Run_MarkdownLinksFootnotes(t *testing.T) {
+	pages := convertVaultPages(t, map[string]string{
+		"Overview.md": note("Overview"),
+		"Linking.md":  note("Some text[^1]", "", "[^1]: Overview of stuff"),
+	}, func(converter *omh.Converter) {
+		converter.BacklinksKey = "backlinks"
+	})
+
+	assert.Equal(t, "---\ntags:\n- any\ntitle: Linking\n---\n\n\nSome text[^1]\n\n[^1]: Overview of stuff", pages["linking.md"])
+	assert.NotContains(t, pages["overview.md"], "backlinks")
+}
+
+func TestConverter_Run_MarkdownLinksWithCode(t *testing.T) {
+	pages := convertVaultPages(t, map[string]string{
+		"Target.md":  note("Target"),
+		"Linking.md": note("See [`code`](Target.md) and `[not](Target.md)`"),
+	}, func(converter *omh.Converter) {
+		converter.BacklinksKey = "backlinks"
+	})
+
+	assert.Contains(t, pages["linking.md"], "See [`code`](/sub-path/target/) and `[not](Target.md)`")
+	assert.Contains(t, pages["target.md"], "backlinks:\n- title: Linking\n  url: /sub-path/linking/\n  excerpt: See `code` and `[not](Target.md)`\n")
+}
@@ -0,0 +1,274 @@
+package omh
+
+import (
+	"regexp"
+	"strings"
+)
+
+// markdownSegmentKind classifies the parts of a Markdown document
+type markdownSegmentKind int
+
+const (
+	// markdownProse is any Markdown, that is not code
+	markdownProse markdownSegmentKind = iota
+
+	// markdownCodeBlock is a fenced (including the fences) or indented code block
+	markdownCodeBlock
+
+	// markdownCodeSpan is inline code (including the backticks)
+	markdownCodeSpan
+
+	// markdownComment is an Obsidian comment (including the `%%` delimiters), that ends at the end of the prose, if
+	// it is not closed
+	markdownComment
+)
+
+// markdownSegment is a consecutive part of a Markdown document
+type markdownSegment struct {
+	Kind markdownSegmentKind
+	Text string
+}
+
+var (
+	markdownHeading   = regexp.MustCompile(`^ {0,3}(#{1,6})[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$`)
+	markdownFenceOpen = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})(.*)$")
+	markdownListItem  = regexp.MustCompile(`^ {0,3}(?:[-+*]|\d{1,9}[.)])(?:[ \t]|$)`)
+	markdownBlankLine = regexp.MustCompile(`\n[ \t]*\n`)
+
+	// markdownContainer matches the blockquote markers and the list item marker at the start of a line
+	markdownContainer = regexp.MustCompile(`^((?:[ \t]{0,3}>[ \t]?)*)([ \t]*(?:[-+*]|\d{1,9}[.)])[ \t]+)?`)
+)
+
+// scanMarkdown splits Markdown content into consecutive segments of prose, code and comments, so that transformations
+// can be applied to prose only. Inline code and comments are only split from prose, if inline is true. Joining the
+// text of all segments results in the original content.
+func scanMarkdown(content string, inline bool) []markdownSegment {
+	lines := strings.SplitAfter(content, "\n")
+	code := markdownCodeBlockLines(lines)
+
+	segments := make([]markdownSegment, 0)
+	for start := 0; start < len(lines); {
+		end := start + 1
+		for end < len(lines) && code[end] == code[start] {
+			end++
+		}
+
+		text := strings.Join(lines[start:end], "")
+		if code[start] {
+			segments = append(segments, markdownSegment{Kind: markdownCodeBlock, Text: text})
+		} else if inline {
+			segments = append(segments, scanMarkdownInline(text)...)
+		} else {
+			segments = append(segments, markdownSegment{Kind: markdownProse, Text: text})
+		}
+		start = end
+	}
+
+	return segments
+}
+
+// mapProse applies fn to all prose in the Markdown content, leaving code blocks, inline code and comments untouched
+func mapProse(content string, fn func(prose string) string) string {
+	return mapMarkdown(content, true, func(prose, _, _ string) string {
+		return fn(prose)
+	})
+}
+
+// mapProseInLines is like mapProse, but also passes the text, that precedes the prose in its first line, like inline
+// code or blockquote markers, and the text, that follows the prose in its last line
+func mapProseInLines(content string, fn func(prose, before, after string) string) string {
+	return mapMarkdown(content, true, fn)
+}
+
+// mapBlocks applies fn to all parts of the Markdown content, that are not code blocks, including inline code and
+// comments
+func mapBlocks(content string, fn func(text string) string) string {
+	return mapMarkdown(content, false, func(text, _, _ string) string {
+		return fn(text)
+	})
+}
+
+func mapMarkdown(content string, inline bool, fn func(prose, before, after string) string) string {
+	var mapped strings.Builder
+	offset := 0
+	for _, segment := range scanMarkdown(content, inline) {
+		if segment.Kind == markdownProse {
+			end := offset + len(segment.Text)
+			after := content[end:]
+			if i := strings.Index(after, "\n"); i > -1 {
+				after = after[:i]
+			}
+			mapped.WriteString(fn(segment.Text, content[strings.LastIndex(content[:offset], "\n")+1:offset], after))
+		} else {
+			mapped.WriteString(segment.Text)
+		}
+		offset += len(segment.Text)
+	}
+	return mapped.String()
+}
+
+// markdownCodeBlockLines returns whether each of the lines is part of a fenced or indented code block. Lines within
+// Obsidian comments are never code.
+func markdownCodeBlockLines(lines []string) []bool {
+	code := make([]bool, len(lines))
+	fence := ""
+	blank, list, indented, comment := true, false, false, false
+	for i, line := range lines {
+		trimmed := strings.TrimRight(line, "\r\n")
+		isBlank := strings.TrimSpace(trimmed) == ""
+
+		// comments continue until the closing delimiter, regardless of code
+		if comment {
+			comment = commentOpen(trimmed, true)
+			blank, indented = false, false
+			continue
+		}
+
+		// fenced code block continues until closing fence of same kind and at least same length
+		if fence != "" {
+			code[i] = true
+			closing := strings.TrimSpace(trimmed)
+			if strings.HasPrefix(closing, fence) && strings.Trim(closing, fence[:1]) == "" && indentation(trimmed) < 4 {
+				fence = ""
+			}
+			blank = false
+			continue
+		}
+
+		if match := markdownFenceOpen.FindStringSubmatch(trimmed); match != nil && !(match[1][0] == '`' && strings.Contains(match[2], "`")) {
+			code[i] = true
+			fence = match[1]
+			blank, indented = false, false
+			continue
+		}
+
+		// indented code blocks can not interrupt paragraphs and are list content within lists
+		if !isBlank && indentation(trimmed) >= 4 && !list && (blank || indented) {
+			code[i] = true
+			blank, indented = false, true
+			continue
+		}
+
+		if !isBlank && indentation(trimmed) < 4 {
+			list = markdownListItem.MatchString(trimmed) || (list && !blank)
+		}
+		blank, indented = isBlank, indented && isBlank
+		comment = commentOpen(trimmed, false)
+	}
+
+	return code
+}
+
+// commentOpen returns whether an Obsidian comment is open at the end of the line, given whether one is open at the
+// start of the line
+func commentOpen(line string, open bool) bool {
+	if open {
+		i := strings.Index(line, "%%")
+		if i < 0 {
+			return true
+		}
+		line = line[i+2:]
+	}
+
+	segments := scanMarkdownInline(line)
+	if len(segments) == 0 {
+		return false
+	}
+	last := segments[len(segments)-1]
+	return last.Kind == markdownComment && (len(last.Text) < 4 || !strings.HasSuffix(last.Text, "%%"))
+}
+
+// scanMarkdownInline splits prose into segments of prose, inline code and comments
+func scanMarkdownInline(text string) []markdownSegment {
+	segments := make([]markdownSegment, 0)
+	start := 0
+	for i := 0; i < len(text); {
+		kind, end := markdownCodeSpan, -1
+		if strings.HasPrefix(text[i:], "%%") {
+			kind, end = markdownComment, len(text)
+			if j := strings.Index(text[i+2:], "%%"); j > -1 {
+				end = i + 2 + j + 2
+			}
+		} else if text[i] == '`' {
+			ticks := countPrefix(text[i:], '`')
+			if end = closingCodeSpan(text, i+ticks, ticks); end < 0 {
+				i += ticks
+				continue
+			}
+		} else {
+			i++
+			continue
+		}
+
+		if start < i {
+			segments = append(segments, markdownSegment{Kind: markdownProse, Text: text[start:i]})
+		}
+		segments = append(segments, markdownSegment{Kind: kind, Text: text[i:end]})
+		start, i = end, end
+	}
+	if start < len(text) {
+		segments = append(segments, markdownSegment{Kind: markdownProse, Text: text[start:]})
+	}
+
+	return segments
+}
+
+// opaqueSpans returns the start and end positions of the inline code and comments in the prose
+func opaqueSpans(text string) [][]int {
+	spans := make([][]int, 0)
+	offset := 0
+	for _, segment := range scanMarkdownInline(text) {
+		if segment.Kind != markdownProse {
+			spans = append(spans, []int{offset, offset + len(segment.Text)})
+		}
+		offset += len(segment.Text)
+	}
+	return spans
+}
+
+// closingCodeSpan returns the end position of a backtick string of the given length at or after from, within the
+// same paragraph, or -1 if there is none
+func closingCodeSpan(text string, from, ticks int) int {
+	limit := len(text)
+	if loc := markdownBlankLine.FindStringIndex(text[from:]); loc != nil {
+		limit = from + loc[0]
+	}
+
+	for i := from; i < limit; {
+		if text[i] != '`' {
+			i++
+			continue
+		}
+		count := countPrefix(text[i:limit], '`')
+		if count == ticks {
+			return i + count
+		}
+		i += count
+	}
+
+	return -1
+}
+
+func countPrefix(text string, char byte) int {
+	count := 0
+	for count < len(text) && text[count] == char {
+		count++
+	}
+	return count
+}
+
+// indentation returns the width of the leading whitespace of the line, with tabs counting as four
+func indentation(line string) int {
+	width := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4 - width%4
+		default:
+			return width
+		}
+	}
+	return width
+}
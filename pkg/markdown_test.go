@@ -0,0 +1,93 @@
+package omh_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConverter_Run_CodeIsUntouched(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		expect  string
+	}{
+		"fenced code block": {
+			content: "[[Target]]\n\n```sh\nif [[ -f x ]]; then echo [[Target]]; fi\n```\n\n[[Target]]",
+			expect:  "[Target](/sub-path/target/)\n\n```sh\nif [[ -f x ]]; then echo [[Target]]; fi\n```\n\n[Target](/sub-path/target/)",
+		},
+		"longer fence": {
+			content: "````\n```\n[[Target]]\n```\n````\n[[Target]]",
+			expect:  "````\n```\n[[Target]]\n```\n````\n[Target](/sub-path/target/)",
+		},
+		"tilde fence": {
+			content: "~~~\n[[Target]]\n~~~\n[[Target]]",
+			expect:  "~~~\n[[Target]]\n~~~\n[Target](/sub-path/target/)",
+		},
+		"unclosed fence": {
+			content: "```\n[[Target]]",
+			expect:  "```\n[[Target]]",
+		},
+		"indented code block": {
+			content: "Paragraph [[Target]]\n\n    [[Target]]\n    [Target](Target.md)\n\n[[Target]]",
+			expect: "Paragraph [Target](/sub-path/target/)\n\n    [[Target]]\n    [Target](Target.md)\n\n" +
+				"[Target](/sub-path/target/)",
+		},
+		"indented paragraph continuation": {
+			content: "Paragraph\n    [[Target]]",
+			expect:  "Paragraph\n    [Target](/sub-path/target/)",
+		},
+		"indented list content": {
+			content: "- Item\n\n    [[Target]]",
+			expect:  "- Item\n\n    [Target](/sub-path/target/)",
+		},
+		"inline code": {
+			content: "Use `[[Target]]` or ``[[Target]] with ` tick`` for [[Target]]",
+			expect:  "Use `[[Target]]` or ``[[Target]] with ` tick`` for [Target](/sub-path/target/)",
+		},
+		"unclosed inline code": {
+			content: "Use `[[Target]]\n\nor [[Target]]`",
+			expect:  "Use `[Target](/sub-path/target/)\n\nor [Target](/sub-path/target/)`",
+		},
+		"block ID in code": {
+			content: "```\n[[#^abc]] ^abc\n```",
+			expect:  "```\n[[#^abc]] ^abc\n```",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pages := convertVault(t, map[string]string{
+				"Target.md": note("Target"),
+				"Source.md": note(test.content),
+			}, nil)
+			assert.Equal(t, test.expect, pages["source.md"])
+		})
+	}
+}
+
+func TestConverter_Run_EmbedSectionWithCode(t *testing.T) {
+	pages := convertVault(t, map[string]string{
+		"Target.md": note(
+			"# Section",
+			"",
+			"```",
+			"# Comment",
+			"text ^abc",
+			"```",
+			"",
+			"# Other",
+		),
+		"Source.md": note("![[Target#Section]]", "", "![[Target#Comment]] ![[Target^abc]]"),
+	}, nil)
+	assert.Equal(t, strings.Join([]string{
+		"# Section",
+		"",
+		"```",
+		"# Comment",
+		"text ^abc",
+		"```",
+		"",
+		"[Target > Comment](/sub-path/target/#comment) [Target > ^abc](/sub-path/target/#abc)",
+	}, "\n"), pages["source.md"])
+}
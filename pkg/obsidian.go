@@ -6,12 +6,17 @@ import (
 	"io/ioutil"
 	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
+// obsidianDateKeys are the front matter keys, that the date of a note is extracted from, in order of preference
+var obsidianDateKeys = []string{"date updated", "date created"}
+
 var obsidianDateFormats = []string{
 	time.RFC3339,
 	"2006-01-02 15:04:05",
@@ -19,6 +24,9 @@ var obsidianDateFormats = []string{
 	"2006-01-02",
 }
 
+// obsidianBlockID matches block reference markers at the end of a line, like `Some paragraph ^abc123`
+var obsidianBlockID = regexp.MustCompile(`(?m)(^|[ \t])\^([a-zA-Z0-9-]+)[ \t]*$`)
+
 // ObsidianFilter includes or excludes a note
 type ObsidianFilter func(ObsidianNote) bool
 
@@ -28,6 +36,12 @@ type ObsidianNote struct {
 	Title     string
 	Content   string
 	Directory *ObsidianDirectory
+
+	// ContentLine is the line number in the note file, that Content starts at
+	ContentLine int
+
+	// FrontMatterLines are the line numbers in the note file of the top-level keys of the front matter
+	FrontMatterLines map[string]int
 }
 
 // HugoFrontMatter returns an updated front-matter metadata, suitable for Hugo pages
@@ -58,9 +72,33 @@ func (note ObsidianNote) HugoFrontMatter(added map[string]interface{}) map[strin
 	return hugo
 }
 
+// Aliases returns the alternative names of the note, from the `aliases` front matter
+func (note ObsidianNote) Aliases() []string {
+	if aliases := note.Strings("aliases"); aliases != nil {
+		return aliases
+	} else if alias := note.String("aliases"); alias != "" {
+		return []string{alias}
+	}
+	return nil
+}
+
+// BlockIDs returns the IDs of all blocks in the note, that are marked for reference (`^abc123`)
+func (note ObsidianNote) BlockIDs() []string {
+	ids := make([]string, 0)
+	for _, segment := range scanMarkdown(note.Content, true) {
+		if segment.Kind != markdownProse {
+			continue
+		}
+		for _, match := range obsidianBlockID.FindAllStringSubmatch(segment.Text, -1) {
+			ids = append(ids, match[2])
+		}
+	}
+	return ids
+}
+
 func (note ObsidianNote) extractDate() (*time.Time, error) {
 	var date string
-	for _, key := range []string{"date updated", "date created"} {
+	for _, key := range obsidianDateKeys {
 		if note.Has(key) {
 			date = note.String(key)
 			break
@@ -99,11 +137,38 @@ func LoadObsidianNote(path string) (ObsidianNote, error) {
 	}
 
 	title := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	matterLines, contentLine := frontMatterLines(raw)
+
+	return ObsidianNote{
+		FrontMatter:      matter,
+		Title:            title,
+		Content:          content,
+		ContentLine:      contentLine,
+		FrontMatterLines: matterLines,
+	}, nil
+}
+
+// loadObsidianNoteWithoutFrontMatter loads an Obsidian note, that lacks front matter, from disk at given path, with the
+// date of the last modification of the file
+func loadObsidianNoteWithoutFrontMatter(path string, modified time.Time) (ObsidianNote, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ObsidianNote{}, err
+	}
+
+	_, content, err := ParseFrontMatterMarkdown(raw)
+	if err != nil && !errors.Is(err, ErrNoFrontMatter) {
+		return ObsidianNote{}, err
+	}
+
+	matterLines, contentLine := frontMatterLines(raw)
 
 	return ObsidianNote{
-		FrontMatter: matter,
-		Title:       title,
-		Content:     content,
+		FrontMatter:      FrontMatter{"date": modified.UTC().Format(time.RFC3339)},
+		Title:            strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		Content:          content,
+		ContentLine:      contentLine,
+		FrontMatterLines: matterLines,
 	}, nil
 }
 
@@ -115,49 +180,237 @@ type ObsidianDirectory struct {
 	Childs []ObsidianDirectory
 	Notes  []ObsidianNote
 	Files  []string
+
+	// Filtered are the notes in the directory, that were rejected by the filter and are not converted
+	Filtered []ObsidianNote
+
+	// FilterReasons are the reasons why the filtered notes were rejected, by their title
+	FilterReasons map[string]string
+
+	// Skipped are the names of the Markdown files in the directory, that are not converted for missing front matter
+	Skipped []string
+
+	// WithoutFrontMatter are the names of the Markdown files in the directory, that are loaded as notes despite missing
+	// front matter
+	WithoutFrontMatter []string
 }
 
 func (directory ObsidianDirectory) Empty() bool {
-	return len(directory.Childs) == 0 && len(directory.Files) == 0 && len(directory.Notes) == 0
+	return len(directory.Childs) == 0 && len(directory.Files) == 0 && len(directory.Notes) == 0 &&
+		len(directory.Filtered) == 0 && len(directory.Skipped) == 0
 }
 
-// LinkMap is the map of Obsidian internal links to Hugo compatible web links ({"Internal Name": "directory/internal-name"}).
-// Note that the Obsidian structure is flat!
+// filteredDirectory returns a copy of the directory tree, that contains the filtered notes as notes and no files
+func (directory ObsidianDirectory) filteredDirectory() ObsidianDirectory {
+	filtered := ObsidianDirectory{
+		Name:   directory.Name,
+		Path:   directory.Path,
+		Childs: make([]ObsidianDirectory, 0, len(directory.Childs)),
+		Notes:  directory.Filtered,
+	}
+	for _, sub := range directory.Childs {
+		filtered.Childs = append(filtered.Childs, sub.filteredDirectory())
+	}
+	return filtered
+}
+
+// LinkMap is the map of Obsidian internal links to Hugo compatible web links ({"Internal Name": "directory/internal-name/"}).
+// Notes and files can be linked with any suffix of their path in the vault, from the bare title (or file name) up to the
+// full path ({"Directory/Internal Name": "directory/internal-name/"}). Links, that match multiple notes or files,
+// resolve to the one with the shortest path in the vault. Aliases of notes, from their front matter, link to the note,
+// unless they collide with the title or path of another note or file.
 func (directory ObsidianDirectory) LinkMap(convert ConvertName) map[string]string {
+	paths := directory.pathMap(convert)
+	candidates := make(map[string][]string)
+	for vaultPath := range paths {
+		parts := strings.Split(vaultPath, "/")
+		for i := range parts {
+			link := strings.Join(parts[i:], "/")
+			candidates[link] = append(candidates[link], vaultPath)
+		}
+	}
+
 	to := make(map[string]string)
-	directory.linkMap(convert, to, "")
+	for link, vaultPaths := range candidates {
+		sortVaultPaths(vaultPaths)
+		if len(vaultPaths) > 1 && !strings.Contains(link, "/") {
+			log.WithFields(log.Fields{
+				"title":    link,
+				"target":   paths[vaultPaths[0]],
+				"shadowed": strings.Join(vaultPaths[1:], ", "),
+			}).Warn("duplicate link found (same Obsidian note in different directories?), using shortest path")
+		}
+		to[link] = paths[vaultPaths[0]]
+	}
+
+	directory.walkNotes(convert, func(note ObsidianNote, vaultPath, target string) {
+		for _, alias := range note.Aliases() {
+			if existing, ok := to[alias]; ok {
+				if existing != target {
+					log.WithFields(log.Fields{
+						"alias":    alias,
+						"note":     vaultPath,
+						"existing": existing,
+					}).Warn("alias collides with other link, ignoring alias")
+				}
+				continue
+			}
+			to[alias] = target
+		}
+	})
+
 	return to
 }
 
-func (directory ObsidianDirectory) linkMap(convert ConvertName, to map[string]string, prefix string) {
-	for _, note := range directory.Notes {
-		target := path.Join(prefix, convert(note.Title))
-		if _, ok := to[note.Title]; ok && to[note.Title] != target {
-			log.WithFields(log.Fields{
-				"title":   note.Title,
-				"target1": to[note.Title],
-				"target2": target,
-			}).Warn("duplicate link found (same Obsidian note in different directories?)")
+// sortVaultPaths sorts paths in the vault by their depth, then alphabetically, so that the shortest path is first
+func sortVaultPaths(vaultPaths []string) {
+	sort.Slice(vaultPaths, func(i, j int) bool {
+		iDepth, jDepth := strings.Count(vaultPaths[i], "/"), strings.Count(vaultPaths[j], "/")
+		if iDepth != jDepth {
+			return iDepth < jDepth
 		}
-		to[note.Title] = target + "/"
-	}
+		return vaultPaths[i] < vaultPaths[j]
+	})
+}
+
+// BlockMap is the map of Hugo compatible web links of notes to the IDs of the blocks they contain ({"directory/internal-name/": {"abc123": true}})
+func (directory ObsidianDirectory) BlockMap(convert ConvertName) map[string]map[string]bool {
+	to := make(map[string]map[string]bool)
+	directory.walkNotes(convert, func(note ObsidianNote, vaultPath, target string) {
+		ids := note.BlockIDs()
+		if len(ids) == 0 {
+			return
+		}
+		to[target] = make(map[string]bool)
+		for _, id := range ids {
+			to[target][id] = true
+		}
+	})
+	return to
+}
+
+// pathMap is the map of the paths of all notes (without `.md` suffix) and files in the vault to their Hugo compatible
+// web links ({"Directory/Internal Name": "directory/internal-name/", "Directory/File.png": "directory/file.png"})
+func (directory ObsidianDirectory) pathMap(convert ConvertName) map[string]string {
+	to := make(map[string]string)
+	directory.walkNotes(convert, func(note ObsidianNote, vaultPath, target string) {
+		to[vaultPath] = target
+	})
+	directory.walkFiles(convert, func(file, vaultPath, target string) {
+		to[vaultPath] = target
+	})
+	return to
+}
+
+// walkNotes calls fn for all notes in the directory and it's sub-directories, together with their path in the vault
+// (without `.md` suffix) and their Hugo compatible web link
+func (directory ObsidianDirectory) walkNotes(convert ConvertName, fn func(note ObsidianNote, vaultPath, target string)) {
+	directory.walk(convert, "", "", func(dir ObsidianDirectory, vaultPrefix, targetPrefix string) {
+		for _, note := range dir.Notes {
+			fn(note, path.Join(vaultPrefix, note.Title), path.Join(targetPrefix, convert(note.Title))+"/")
+		}
+	})
+}
+
+// walkFiles calls fn for all (static) files in the directory and it's sub-directories, together with their path in the
+// vault and their Hugo compatible web link
+func (directory ObsidianDirectory) walkFiles(convert ConvertName, fn func(file, vaultPath, target string)) {
+	directory.walk(convert, "", "", func(dir ObsidianDirectory, vaultPrefix, targetPrefix string) {
+		for _, file := range dir.Files {
+			ext := path.Ext(file)
+			fn(file, path.Join(vaultPrefix, file), path.Join(targetPrefix, convert(strings.TrimSuffix(file, ext))+ext))
+		}
+	})
+}
+
+func (directory ObsidianDirectory) walk(convert ConvertName, vaultPrefix, targetPrefix string, fn func(dir ObsidianDirectory, vaultPrefix, targetPrefix string)) {
+	fn(directory, vaultPrefix, targetPrefix)
 	for _, sub := range directory.Childs {
-		sub.linkMap(convert, to, path.Join(prefix, convert(sub.Name)))
+		sub.walk(convert, path.Join(vaultPrefix, sub.Name), path.Join(targetPrefix, convert(sub.Name)), fn)
 	}
 }
 
+// PublishMode is how the publish flag in the front matter of notes (`publish: true`), as used by Obsidian Publish, is
+// respected when loading notes
+type PublishMode string
+
+const (
+	// PublishIgnore ignores the publish flag
+	PublishIgnore PublishMode = "ignore"
+
+	// PublishRequired only loads notes, that are flagged with `publish: true`
+	PublishRequired PublishMode = "required"
+
+	// PublishRespect never loads notes, that are flagged with `publish: false`, regardless of the filter
+	PublishRespect PublishMode = "respect"
+)
+
+// DefaultPublishKey is the front matter key of the publish flag, as used by Obsidian Publish
+const DefaultPublishKey = "publish"
+
+// ObsidianLoader reads notes and sub-directories from an Obsidian vault
+type ObsidianLoader struct {
+
+	// Filter includes or excludes notes (or nil, in case all notes are included)
+	Filter ObsidianFilter
+
+	// Recurse enables reading sub-directories
+	Recurse bool
+
+	// PublishMode is how the publish flag of notes is respected (defaults to PublishIgnore)
+	PublishMode PublishMode
+
+	// PublishKey is the front matter key of the publish flag (defaults to DefaultPublishKey)
+	PublishKey string
+
+	// WithoutFrontMatter enables loading Markdown files without front matter as notes with empty front matter, and
+	// the date of the last modification of the file, instead of skipping them
+	WithoutFrontMatter bool
+
+	// Ignore are gitignore-style patterns of notes, files and directories relative to the vault, that are not loaded,
+	// in addition to the patterns in the IgnoreFile of each directory
+	Ignore []string
+}
+
 // LoadObsidianDirectory reads all notes and sub-directories within a directory in an Obsidian vault
 func LoadObsidianDirectory(path string, filter ObsidianFilter, recurse bool) (root ObsidianDirectory, err error) {
+	return ObsidianLoader{Filter: filter, Recurse: recurse}.Load(path)
+}
+
+// Load reads all notes and sub-directories within a directory in an Obsidian vault
+func (loader ObsidianLoader) Load(path string) (ObsidianDirectory, error) {
+	patterns := make([]ignorePattern, 0, len(loader.Ignore))
+	for _, line := range loader.Ignore {
+		if pattern, ok := parseIgnorePattern("", line); ok {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return loader.load(path, "", patterns)
+}
+
+// load reads the directory located at vaultPath in the vault, ignoring the notes, files and directories matching the
+// patterns
+func (loader ObsidianLoader) load(path, vaultPath string, patterns []ignorePattern) (root ObsidianDirectory, err error) {
 	fis, err := ioutil.ReadDir(path)
 	if err != nil {
 		return
 	}
 
+	ignore, err := loadIgnoreFile(path, vaultPath)
+	if err != nil {
+		return
+	}
+	patterns = append(patterns[:len(patterns):len(patterns)], ignore...)
+
 	root.Path = path
 	root.Name = filepath.Base(path)
 	root.Childs = make([]ObsidianDirectory, 0)
 	root.Files = make([]string, 0)
 	root.Notes = make([]ObsidianNote, 0)
+	root.Filtered = make([]ObsidianNote, 0)
+	root.FilterReasons = make(map[string]string)
+	root.Skipped = make([]string, 0)
+	root.WithoutFrontMatter = make([]string, 0)
 	for _, fi := range fis {
 
 		// ignore hidden
@@ -166,14 +419,21 @@ func LoadObsidianDirectory(path string, filter ObsidianFilter, recurse bool) (ro
 		}
 
 		p := filepath.Join(path, fi.Name())
+		vp := strings.TrimPrefix(vaultPath+"/"+fi.Name(), "/")
+
+		// ignore paths matching patterns
+		if ignored(patterns, vp, fi.IsDir()) {
+			log.WithField("path", p).Debug("ignore path matching pattern")
+			continue
+		}
 
 		// recurse directories
 		if fi.IsDir() {
-			if !recurse {
+			if !loader.Recurse {
 				continue
 			}
 			log.WithField("directory", p).Debug("traverse sub-directory")
-			sub, err := LoadObsidianDirectory(p, filter, true)
+			sub, err := loader.load(p, vp, patterns)
 			if err != nil {
 				return ObsidianDirectory{}, err
 			} else if sub.Empty() {
@@ -190,20 +450,31 @@ func LoadObsidianDirectory(path string, filter ObsidianFilter, recurse bool) (ro
 			note, err := LoadObsidianNote(p)
 			if err != nil {
 
-				// ignore markdown files that lack front-matter
-				if errors.Is(err, ErrNoFrontMatter) {
+				// ignore markdown files that lack front-matter, unless they are loaded with empty front matter
+				if errors.Is(err, ErrNoFrontMatter) && loader.WithoutFrontMatter {
+					log.WithFields(log.Fields{"file": p}).Info("load file with missing front matter")
+					note, err = loadObsidianNoteWithoutFrontMatter(p, fi.ModTime())
+					if err != nil {
+						return ObsidianDirectory{}, err
+					}
+					root.WithoutFrontMatter = append(root.WithoutFrontMatter, fi.Name())
+				} else if errors.Is(err, ErrNoFrontMatter) {
 					log.WithFields(log.Fields{"file": p}).Warn("ignore file with missing front matter")
+					root.Skipped = append(root.Skipped, fi.Name())
 					continue
+				} else {
+					return ObsidianDirectory{}, err
 				}
-				return ObsidianDirectory{}, err
 			}
 
-			if filter != nil && !filter(note) {
-				log.WithField("note", note.Title).Info("note filtered out")
+			note.Directory = &root
+			if reason := loader.reject(note); reason != "" {
+				log.WithFields(log.Fields{"note": note.Title, "reason": reason}).Info("note filtered out")
+				root.Filtered = append(root.Filtered, note)
+				root.FilterReasons[note.Title] = reason
 				continue
 			}
 
-			note.Directory = &root
 			root.Notes = append(root.Notes, note)
 
 			// handle other (static) files
@@ -216,3 +487,24 @@ func LoadObsidianDirectory(path string, filter ObsidianFilter, recurse bool) (ro
 
 	return
 }
+
+// reject returns the reason why the note is filtered out, or an empty string if the note is loaded
+func (loader ObsidianLoader) reject(note ObsidianNote) string {
+	key := loader.PublishKey
+	if key == "" {
+		key = DefaultPublishKey
+	}
+	published, flagged := note.FrontMatter[key].(bool)
+	if !flagged && note.Has(key) {
+		published, flagged = note.String(key) == "true", true
+	}
+
+	if loader.PublishMode == PublishRequired && !published {
+		return fmt.Sprintf("%s is not true", key)
+	} else if loader.PublishMode == PublishRespect && flagged && !published {
+		return fmt.Sprintf("%s is false", key)
+	} else if loader.Filter != nil && !loader.Filter(note) {
+		return "rejected by filter"
+	}
+	return ""
+}
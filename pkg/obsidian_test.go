@@ -1,9 +1,11 @@
 package omh_test
 
 import (
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -161,6 +163,13 @@ func TestLoadObsidianNote(t *testing.T) {
 			"date updated": "2021-12-24 11:12:13",
 			"tags":         []interface{}{"aaa"},
 		},
+		ContentLine: 10,
+		FrontMatterLines: map[string]int{
+			"tags":         2,
+			"aliases":      4,
+			"date updated": 6,
+			"date created": 7,
+		},
 	}, note)
 }
 
@@ -227,6 +236,62 @@ func TestObsidianDirectory_LinkMap(t *testing.T) {
 				"Foo Level 2a": "sub directory 1/foo level 2a/",
 				"Foo Level 2b": "sub directory 2/foo level 2b/",
 				"Foo Level 3":  "sub directory 1/sub directory 3/foo level 3/",
+
+				"Sub Directory 1/Bla Level 2a":                "sub directory 1/bla level 2a/",
+				"Sub Directory 1/Foo Level 2a":                "sub directory 1/foo level 2a/",
+				"Sub Directory 1/Sub Directory 3/Bla Level 3": "sub directory 1/sub directory 3/bla level 3/",
+				"Sub Directory 1/Sub Directory 3/Foo Level 3": "sub directory 1/sub directory 3/foo level 3/",
+				"Sub Directory 2/Bla Level 2b":                "sub directory 2/bla level 2b/",
+				"Sub Directory 2/Foo Level 2b":                "sub directory 2/foo level 2b/",
+				"Sub Directory 3/Bla Level 3":                 "sub directory 1/sub directory 3/bla level 3/",
+				"Sub Directory 3/Foo Level 3":                 "sub directory 1/sub directory 3/foo level 3/",
+			},
+		},
+		"duplicates": {
+			directory: omh.ObsidianDirectory{
+				Childs: []omh.ObsidianDirectory{
+					{
+						Name:  "B",
+						Notes: []omh.ObsidianNote{{Title: "Dup"}},
+						Childs: []omh.ObsidianDirectory{
+							{Name: "C", Notes: []omh.ObsidianNote{{Title: "Dup"}}},
+						},
+					},
+					{Name: "A", Notes: []omh.ObsidianNote{{Title: "Dup"}}},
+				},
+			},
+			linkMap: map[string]string{
+				"Dup":     "a/dup/",
+				"A/Dup":   "a/dup/",
+				"B/Dup":   "b/dup/",
+				"C/Dup":   "b/c/dup/",
+				"B/C/Dup": "b/c/dup/",
+			},
+		},
+		"aliases": {
+			directory: omh.ObsidianDirectory{
+				Notes: []omh.ObsidianNote{
+					{Title: "Kubernetes", FrontMatter: omh.FrontMatter{"aliases": []interface{}{"K8s", "Other", "Kubernetes"}}},
+					{Title: "Other", FrontMatter: omh.FrontMatter{"aliases": "K8s"}},
+				},
+			},
+			linkMap: map[string]string{
+				"Kubernetes": "kubernetes/",
+				"K8s":        "kubernetes/",
+				"Other":      "other/",
+			},
+		},
+		"files": {
+			directory: omh.ObsidianDirectory{
+				Files: []string{"Image.PNG"},
+				Childs: []omh.ObsidianDirectory{
+					{Name: "Sub", Files: []string{"Some File.txt"}},
+				},
+			},
+			linkMap: map[string]string{
+				"Image.PNG":         "image.PNG",
+				"Some File.txt":     "sub/some file.txt",
+				"Sub/Some File.txt": "sub/some file.txt",
 			},
 		},
 	}
@@ -238,6 +303,54 @@ func TestObsidianDirectory_LinkMap(t *testing.T) {
 	}
 }
 
+func TestObsidianNote_Aliases(t *testing.T) {
+	assert.Nil(t, omh.ObsidianNote{}.Aliases())
+	assert.Equal(t, []string{"one"}, omh.ObsidianNote{FrontMatter: omh.FrontMatter{"aliases": "one"}}.Aliases())
+	assert.Equal(t, []string{"one", "two"}, omh.ObsidianNote{
+		FrontMatter: omh.FrontMatter{"aliases": []interface{}{"one", "two"}},
+	}.Aliases())
+}
+
+func TestObsidianNote_BlockIDs(t *testing.T) {
+	note := omh.ObsidianNote{
+		Content: strings.Join([]string{
+			"A paragraph ^first",
+			"",
+			"- a list item ^second-2",
+			"",
+			"| a | table |",
+			"",
+			"^third",
+			"",
+			"Not a^block and not ^a block",
+		}, "\n"),
+	}
+
+	assert.Equal(t, []string{"first", "second-2", "third"}, note.BlockIDs())
+}
+
+func TestObsidianDirectory_BlockMap(t *testing.T) {
+	directory := omh.ObsidianDirectory{
+		Notes: []omh.ObsidianNote{
+			{Title: "Foo", Content: "A paragraph ^foo1\n\nAnother ^foo2"},
+			{Title: "Bar", Content: "Without blocks"},
+		},
+		Childs: []omh.ObsidianDirectory{
+			{
+				Name: "Sub",
+				Notes: []omh.ObsidianNote{
+					{Title: "Baz", Content: "A paragraph ^baz"},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, map[string]map[string]bool{
+		"foo/":     {"foo1": true, "foo2": true},
+		"sub/baz/": {"baz": true},
+	}, directory.BlockMap(strings.ToLower))
+}
+
 func TestLoadObsidianDirectory(t *testing.T) {
 	directory, err := omh.LoadObsidianDirectory(filepath.Join("fixtures", "source", "Sub Directory"), nil, false)
 	require.NoError(t, err)
@@ -247,6 +360,18 @@ func TestLoadObsidianDirectory(t *testing.T) {
 	assert.Equal(t, []string{"Circle Thing.svg", "Something Static.txt"}, directory.Files)
 	require.Len(t, directory.Notes, 1)
 	assert.Equal(t, "Additional Note", directory.Notes[0].Title)
+	assert.Equal(t, []string{"Incomplete Note.md", "Ruled Note.md"}, directory.Skipped)
+}
+
+func TestLoadObsidianDirectory_WithoutFrontMatter(t *testing.T) {
+	directory, err := omh.ObsidianLoader{WithoutFrontMatter: true}.Load(filepath.Join("fixtures", "source", "Sub Directory"))
+	require.NoError(t, err)
+
+	assert.Empty(t, directory.Skipped)
+	assert.Equal(t, []string{"Incomplete Note.md", "Ruled Note.md"}, directory.WithoutFrontMatter)
+	require.Len(t, directory.Notes, 3)
+	assert.Equal(t, "Ruled Note", directory.Notes[2].Title)
+	assert.Equal(t, "Intro without front matter\n\n---\n\nMore text after the rule", directory.Notes[2].Content)
 }
 
 func TestLoadObsidianDirectory_Recursive(t *testing.T) {
@@ -293,9 +418,102 @@ func TestLoadObsidianDirectory_Filtered(t *testing.T) {
 
 	require.Len(t, directory.Notes, 1)
 	assert.Equal(t, "Other Note", directory.Notes[0].Title)
+	require.Len(t, directory.Filtered, 1)
+	assert.Equal(t, "Some Note", directory.Filtered[0].Title)
 
 	require.Len(t, directory.Childs, 1)
 	assert.Equal(t, "Sub Directory", directory.Childs[0].Name)
 	require.Len(t, directory.Childs[0].Notes, 1)
 	assert.Equal(t, "Additional Note", directory.Childs[0].Notes[0].Title)
 }
+
+func TestObsidianLoader_Publish(t *testing.T) {
+	source := writeVault(t, map[string]string{
+		"Published.md":   "---\npublish: true\ntags: [private]\n---\n\nPublished",
+		"Unpublished.md": "---\npublish: false\n---\n\nUnpublished",
+		"Quoted.md":      "---\nshare: \"true\"\n---\n\nQuoted",
+		"Unflagged.md":   note("Unflagged"),
+	})
+	rejectPrivate := func(note omh.ObsidianNote) bool {
+		return note.String("tags") != "[private]"
+	}
+
+	tests := map[string]struct {
+		loader   omh.ObsidianLoader
+		notes    []string
+		filtered map[string]string
+	}{
+		"ignore": {
+			loader:   omh.ObsidianLoader{Filter: rejectPrivate},
+			notes:    []string{"Quoted", "Unflagged", "Unpublished"},
+			filtered: map[string]string{"Published": "rejected by filter"},
+		},
+		"required": {
+			loader: omh.ObsidianLoader{PublishMode: omh.PublishRequired},
+			notes:  []string{"Published"},
+			filtered: map[string]string{
+				"Quoted":      "publish is not true",
+				"Unflagged":   "publish is not true",
+				"Unpublished": "publish is not true",
+			},
+		},
+		"required with key": {
+			loader: omh.ObsidianLoader{PublishMode: omh.PublishRequired, PublishKey: "share"},
+			notes:  []string{"Quoted"},
+			filtered: map[string]string{
+				"Published":   "share is not true",
+				"Unflagged":   "share is not true",
+				"Unpublished": "share is not true",
+			},
+		},
+		"respect": {
+			loader:   omh.ObsidianLoader{PublishMode: omh.PublishRespect, Filter: rejectPrivate},
+			notes:    []string{"Quoted", "Unflagged"},
+			filtered: map[string]string{"Published": "rejected by filter", "Unpublished": "publish is false"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			directory, err := test.loader.Load(source)
+			require.NoError(t, err)
+
+			notes := make([]string, 0)
+			for _, note := range directory.Notes {
+				notes = append(notes, note.Title)
+			}
+			assert.Equal(t, test.notes, notes)
+			assert.Equal(t, test.filtered, directory.FilterReasons)
+			assert.Len(t, directory.Filtered, len(test.filtered))
+		})
+	}
+}
+
+func TestObsidianLoader_WithoutFrontMatter(t *testing.T) {
+	source := writeVault(t, map[string]string{
+		"Plain.md":   "# Plain\n\nLinks to [[Other]]\n",
+		"Other.md":   note("Other"),
+		"Sub/Raw.md": "Raw",
+	})
+	modified := time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)
+	require.NoError(t, os.Chtimes(filepath.Join(source, "Plain.md"), modified, modified))
+
+	directory, err := omh.ObsidianLoader{Recurse: true}.Load(source)
+	require.NoError(t, err)
+	assert.Len(t, directory.Notes, 1)
+	assert.Equal(t, []string{"Plain.md"}, directory.Skipped)
+	assert.Empty(t, directory.WithoutFrontMatter)
+
+	directory, err = omh.ObsidianLoader{Recurse: true, WithoutFrontMatter: true}.Load(source)
+	require.NoError(t, err)
+	require.Len(t, directory.Notes, 2)
+	assert.Empty(t, directory.Skipped)
+	assert.Equal(t, []string{"Plain.md"}, directory.WithoutFrontMatter)
+	assert.Equal(t, []string{"Raw.md"}, directory.Childs[0].WithoutFrontMatter)
+
+	plain := directory.Notes[1]
+	assert.Equal(t, "Plain", plain.Title)
+	assert.Equal(t, "# Plain\n\nLinks to [[Other]]", plain.Content)
+	assert.Equal(t, omh.FrontMatter{"date": "2023-04-05T06:07:08Z"}, plain.FrontMatter)
+}
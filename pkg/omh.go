@@ -7,6 +7,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -37,23 +38,145 @@ type Converter struct {
 	// TagsKey is name of the key in front-matter that should contain tags (or unset, in case not changed)
 	TagsKey string
 
-	linkMap map[string]string
+	// AnchorStyle is the algorithm used to render links to headings, must match the Hugo setup (defaults to AnchorStyleGitHub)
+	AnchorStyle AnchorStyle
+
+	// EmbedMode is how embedded notes (`![[Some Note]]`) are rendered (defaults to EmbedInline)
+	EmbedMode EmbedMode
+
+	// EmbedDepth is the maximum depth of (recursively) inlined embedded notes (defaults to DefaultEmbedDepth)
+	EmbedDepth int
+
+	// EmbedKinds maps (lower case) file extensions, like `.mp4`, to the kind of embedded files, in addition to and
+	// overriding DefaultEmbedKinds
+	EmbedKinds map[string]EmbedKind
+
+	// SizedImages is how embedded images with dimensions are rendered (defaults to SizedImageHTML)
+	SizedImages SizedImageMode
+
+	// LinkMode is how links to notes are rendered (defaults to LinkURL)
+	LinkMode LinkMode
+
+	// HugoAliases enables rendering the aliases of notes as Hugo `aliases`, so that the URLs built from the aliases
+	// redirect to the note
+	HugoAliases bool
+
+	// BacklinksKey is the name of the key in front-matter that should contain the backlinks from other notes (or
+	// unset, in case backlinks are not rendered)
+	BacklinksKey string
+
+	// GraphFile is the name of the file in `static/<sub-path>`, that the Graph of all notes is written to as JSON (or
+	// unset, in case no graph is written)
+	GraphFile string
+
+	// CalloutMode is how Obsidian callouts (`> [!note] Title`) are rendered (defaults to CalloutBlockquote)
+	CalloutMode CalloutMode
+
+	// InlineTransforms maps names to additional transforms of inline syntax, which override DefaultInlineTransforms
+	// with the same name. Transforms, that are mapped to nil, are disabled.
+	InlineTransforms map[string]InlineTransform
+
+	// ExpandTags enables adding all ancestors of nested tags, like `project` and `project/alpha` for
+	// `project/alpha/backend`, to the tags in front-matter, with ConvertName applied to each level
+	ExpandTags bool
+
+	// TagLinks enables rewriting inline tags (`#some-tag`) into links to the Hugo taxonomy term page of the tag
+	TagLinks bool
+
+	// HTMLComments enables rendering Obsidian comments (`%% ... %%`) as HTML comments, instead of removing them
+	HTMLComments bool
+
+	// Strict enables failing the conversion with a StrictError, that lists all unresolved links, missing embeds,
+	// unparsable dates and duplicate titles in the vault, instead of only logging warnings
+	Strict bool
+
+	// ReportFile is the path of the file, that the Report of the conversion is written to as JSON (or unset, in case
+	// no report is written)
+	ReportFile string
+
+	// FilteredLinks is how links to notes, that were rejected by the filter, are rendered (defaults to
+	// FilteredLinkText)
+	FilteredLinks FilteredLinkPolicy
+
+	// FilteredPlaceholder is the text, that links to filtered notes are replaced with when FilteredLinks is
+	// FilteredLinkPlaceholder (defaults to DefaultFilteredPlaceholder)
+	FilteredPlaceholder string
+
+	linkMap         map[string]string
+	pathMap         map[string]string
+	filteredLinkMap map[string]string
+	filteredPathMap map[string]string
+	blockMap        map[string]map[string]bool
+	notes           map[string]ObsidianNote
+	vaultPaths      map[string]string
+	backlinks       map[string][]Backlink
+	report          *Report
 }
 
 func (c *Converter) init() {
 	c.linkMap = c.ObsidianRoot.LinkMap(c.ConvertName)
+	c.pathMap = c.ObsidianRoot.pathMap(c.ConvertName)
+	c.blockMap = c.ObsidianRoot.BlockMap(c.ConvertName)
+	filtered := c.ObsidianRoot.filteredDirectory()
+	c.filteredLinkMap = filtered.LinkMap(c.ConvertName)
+	c.filteredPathMap = filtered.pathMap(c.ConvertName)
+	c.notes = make(map[string]ObsidianNote)
+	c.vaultPaths = make(map[string]string)
+	c.ObsidianRoot.walkNotes(c.ConvertName, func(note ObsidianNote, vaultPath, target string) {
+		c.notes[target] = note
+		c.vaultPaths[target] = vaultPath
+	})
+	if c.BacklinksKey != "" {
+		c.backlinks = c.backlinkMap()
+	}
+	c.report = c.newReport()
+}
+
+// Report returns the report of the last run
+func (c Converter) Report() Report {
+	if c.report == nil {
+		return Report{}
+	}
+	return *c.report
 }
 
 // Run transforms and writes all Obsidian root found Markdown files into Hugo suitable Markdown files as well as copies all used static
 func (c *Converter) Run() (err error) {
 	c.init()
 
+	if c.ReportFile != "" {
+		defer func() {
+			if reportErr := c.report.write(c.ReportFile); reportErr != nil && err == nil {
+				err = fmt.Errorf("failed to write report: %w", reportErr)
+			}
+		}()
+	}
+
+	if c.Strict || c.ReportFile != "" || c.FilteredLinks == FilteredLinkFail {
+		c.report.Problems = c.problems()
+	}
+	if c.Strict && len(c.report.Problems) > 0 {
+		return &StrictError{Problems: c.report.Problems}
+	}
+
+	// refuse to convert anything, if any note links to filtered notes
+	if c.FilteredLinks == FilteredLinkFail {
+		if problems := filteredLinkProblems(c.report.Problems); len(problems) > 0 {
+			return &StrictError{Problems: problems}
+		}
+	}
+
 	err = c.processFiles(c.ObsidianRoot, filepath.Join(c.HugoRoot, "static", c.SubPath))
 	if err != nil {
 		return
 	}
 
-	err = c.processNotes(c.ObsidianRoot, filepath.Join(c.HugoRoot, "content", c.SubPath))
+	err = c.processNotes(c.ObsidianRoot, filepath.Join(c.HugoRoot, "content", c.SubPath), "")
+	if err != nil || c.GraphFile == "" {
+		return
+	}
+
+	err = c.writeGraph(filepath.Join(c.HugoRoot, "static", c.SubPath, c.GraphFile))
 
 	return
 }
@@ -64,9 +187,7 @@ func (c Converter) processFiles(obsidianDir ObsidianDirectory, hugoDir string) e
 		return err
 	}
 
-	// move all files and make them link-able
-	startWord := filepath.Join("static", c.SubPath)
-	startLen := len(startWord) + 1
+	// move all files
 	for _, file := range obsidianDir.Files {
 		src := filepath.Join(obsidianDir.Path, file)
 		ext := filepath.Ext(file)
@@ -74,11 +195,7 @@ func (c Converter) processFiles(obsidianDir ObsidianDirectory, hugoDir string) e
 		if err = c.copyFile(src, dst); err != nil {
 			return err
 		}
-
-		// add to link map, so will be replaced later on
-		idx := strings.Index(dst, startWord)
-		rel := dst[idx+startLen:]
-		c.linkMap[file] = rel
+		c.report.Files = append(c.report.Files, ReportEntry{Source: c.vaultFile(src), Output: c.reportPath(dst)})
 	}
 
 	// recurse
@@ -113,7 +230,7 @@ func (c ConvertName) copyFile(from, to string) error {
 	return err
 }
 
-func (c Converter) processNotes(obsidianDir ObsidianDirectory, hugoDir string) error {
+func (c Converter) processNotes(obsidianDir ObsidianDirectory, hugoDir, prefix string) error {
 	err := os.MkdirAll(hugoDir, 0755)
 	if err != nil && !os.IsExist(err) {
 		return err
@@ -121,16 +238,21 @@ func (c Converter) processNotes(obsidianDir ObsidianDirectory, hugoDir string) e
 
 	// move all notes
 	for _, note := range obsidianDir.Notes {
-		hugoContent, err := c.convertNote(note)
+		name := c.ConvertName(note.Title)
+		hugoContent, err := c.convertNote(note, path.Join(prefix, name)+"/")
 		if err != nil {
 			return fmt.Errorf("failed to convert %s: %w", note.Title, err)
 		}
 
-		hugoPath := filepath.Join(hugoDir, c.ConvertName(note.Title)) + ".md"
+		hugoPath := filepath.Join(hugoDir, name) + ".md"
 		err = ioutil.WriteFile(hugoPath, hugoContent, 0644)
 		if err != nil {
 			return fmt.Errorf("failed to write %s: %w", hugoPath, err)
 		}
+		c.report.Notes = append(c.report.Notes, ReportEntry{
+			Source: c.vaultFile(filepath.Join(obsidianDir.Path, note.Title+".md")),
+			Output: c.reportPath(hugoPath),
+		})
 	}
 
 	// recurse
@@ -139,8 +261,8 @@ func (c Converter) processNotes(obsidianDir ObsidianDirectory, hugoDir string) e
 			continue
 		}
 
-		hugoSubPath := filepath.Join(hugoDir, c.ConvertName(obsidianSubDir.Name))
-		if err := c.processNotes(obsidianSubDir, hugoSubPath); err != nil {
+		name := c.ConvertName(obsidianSubDir.Name)
+		if err := c.processNotes(obsidianSubDir, filepath.Join(hugoDir, name), path.Join(prefix, name)); err != nil {
 			return err
 		}
 	}
@@ -149,15 +271,20 @@ func (c Converter) processNotes(obsidianDir ObsidianDirectory, hugoDir string) e
 }
 
 var (
-	obsidianLink = regexp.MustCompile(`\[\[.+?\]\]`)
+	obsidianLink = regexp.MustCompile(`!?\[\[.+?\]\]`)
 )
 
-func (c Converter) convertNote(note ObsidianNote) ([]byte, error) {
+func (c Converter) convertNote(note ObsidianNote, target string) ([]byte, error) {
 	buf := bytes.NewBuffer(nil)
 
 	// write front matter
 	buf.WriteString("---\n")
 	matter := note.HugoFrontMatter(c.FrontMatter)
+	if _, added := c.FrontMatter["tags"]; !added {
+		if tags := note.Tags(); len(tags) > 0 {
+			matter["tags"] = c.tagTerms(tags)
+		}
+	}
 	if c.TagsKey != "" && c.TagsKey != "tags" {
 		tags, ok := matter["tags"]
 		if ok {
@@ -165,6 +292,14 @@ func (c Converter) convertNote(note ObsidianNote) ([]byte, error) {
 			delete(matter, "tags")
 		}
 	}
+	if backlinks := c.backlinks[target]; len(backlinks) > 0 {
+		matter[c.BacklinksKey] = backlinks
+	}
+	if c.HugoAliases {
+		if aliases := c.hugoAliases(note, target); len(aliases) > 0 {
+			matter["aliases"] = aliases
+		}
+	}
 
 	frontMatter, err := yaml.Marshal(matter)
 	if err != nil {
@@ -173,30 +308,182 @@ func (c Converter) convertNote(note ObsidianNote) ([]byte, error) {
 	buf.Write(frontMatter)
 	buf.WriteString("---\n\n\n")
 
-	// replace internal links in content with "regular" links
-	content := obsidianLink.ReplaceAllStringFunc(note.Content, func(s string) string {
-		s = strings.TrimPrefix(s, "[[")
-		s = strings.TrimSuffix(s, "]]")
+	buf.WriteString(c.convertContent(note, target, note.Content, []string{target}))
+
+	return buf.Bytes(), nil
+
+}
+
+// hugoAliases returns the URLs built from the aliases of the note, located at target, in the same directory
+func (c Converter) hugoAliases(note ObsidianNote, target string) []string {
+	directory := path.Dir(strings.TrimSuffix(target, "/"))
+	aliases := make([]string, 0)
+	seen := map[string]bool{"/" + path.Join(c.SubPath, target) + "/": true}
+	for _, alias := range note.Aliases() {
+		url := "/" + path.Join(c.SubPath, directory, c.ConvertName(alias)) + "/"
+		if !seen[url] {
+			seen[url] = true
+			aliases = append(aliases, url)
+		}
+	}
+	return aliases
+}
+
+// convertContent rewrites the Obsidian specific syntax in content of note, which is located at target, into Hugo
+// compatible Markdown. The embedded list contains the targets (with optional anchor) of all notes the content is
+// (transitively) embedded in, starting with the converted page and ending with the content itself.
+func (c Converter) convertContent(note ObsidianNote, target, content string, embedded []string) string {
+
+	// remove comments first, so that nothing within comments is rewritten
+	content = c.convertComments(content)
+
+	// rewrite callouts before links, so that code blocks within callouts are recognized as such
+	content = c.convertCallouts(content)
+
+	// replace Markdown links to notes and files with Hugo compatible links, in whole blocks, so that links with inline
+	// code in their text are recognized
+	filtered := c.filteredReferences(target, content)
+	content = mapBlocks(content, func(text string) string {
+		return c.convertMarkdownLinks(note, target, text, embedded, filtered)
+	})
+
+	transforms := c.inlineTransforms()
+	return mapProseInLines(content, func(prose, before, after string) string {
 
-		link, title := s, s
-		if i := strings.Index(s, "|"); i > -1 {
-			link, title = s[0:i], s[i+1:]
+		// replace block reference markers with anchors, that links can point to
+		prose = obsidianBlockID.ReplaceAllString(prose, `$1<span id="$2"></span>`)
+
+		// rewrite inline syntax, like highlights, before links, so that embedded content is not transformed twice
+		for _, transform := range transforms {
+			prose = transform(prose)
+		}
+		if c.TagLinks {
+			prose = c.convertTags(prose)
+		}
+
+		// replace internal links in content with "regular" links
+		return c.convertObsidianLinks(note, target, prose, before, after, embedded)
+	})
+}
+
+// convertObsidianLinks rewrites all Obsidian links in the content, which is preceded by before in its first line and
+// followed by after in its last line. See convertContent for the other parameters.
+func (c Converter) convertObsidianLinks(note ObsidianNote, target, content, before, after string, embedded []string) string {
+	var converted strings.Builder
+	last := 0
+	for _, loc := range obsidianLink.FindAllStringIndex(content, -1) {
+		replacement, inlined := c.convertObsidianLink(note, target, content[loc[0]:loc[1]], embedded)
+		if !inlined {
+			converted.WriteString(content[last:loc[0]])
+			converted.WriteString(replacement)
+			last = loc[1]
+			continue
+		}
+
+		// inlined notes are blocks of their own, so that headings, lists and code in them are rendered as such
+		line := content[strings.LastIndex(content[:loc[0]], "\n")+1 : loc[0]]
+		if !strings.Contains(content[:loc[0]], "\n") {
+			line = before + line
+		}
+		rest := content[loc[1]:]
+		if !strings.Contains(rest, "\n") {
+			rest += after
 		}
+		block, separated, skip := embedBlock(line, rest, replacement)
+		if separated {
+			converted.WriteString(strings.TrimRight(content[last:loc[0]], " \t"))
+		} else {
+			converted.WriteString(content[last:loc[0]])
+		}
+		converted.WriteString(block)
+		last = loc[1] + skip
+	}
+	converted.WriteString(content[last:])
+
+	return converted.String()
+}
+
+// convertObsidianLink returns the Hugo compatible Markdown of the Obsidian link s in the content of the note located at
+// target, and whether it is the inlined content of an embedded note. See convertContent for the other parameters.
+func (c Converter) convertObsidianLink(note ObsidianNote, target, s string, embedded []string) (string, bool) {
+	link := ParseObsidianLink(s)
+	title := link.DisplayTitle()
+
+	anchor := ""
+	if link.Block != "" {
+		anchor = "#" + link.Block
+	} else if link.Heading != "" {
+		anchor = "#" + c.AnchorStyle.Anchor(link.Heading)
+	}
 
-		target, ok := c.linkMap[link]
-		if !ok {
+	// links to headings or blocks within the same note stay relative, unless the note is embedded elsewhere
+	linkTarget := target
+	if link.Target == "" && anchor != "" && !link.Embed && len(embedded) == 1 {
+		return fmt.Sprintf("[%s](%s)", title, anchor), false
+	} else if link.Target != "" {
+		var ok bool
+		linkTarget, ok = c.resolveLink(target, link.Target)
+		if filteredTarget, filtered := c.resolveFilteredLink(target, link.Target); !ok && filtered {
+			return c.filteredLink(title, filteredTarget), false
+		} else if !ok {
 			log.WithFields(log.Fields{
 				"link-title":  title,
-				"link-target": link,
+				"link-target": link.Target,
 				"note":        note.Title,
 			}).Warn("missing target for note")
-			return title
+			return title, false
 		}
+	}
+	if link.Block != "" && !c.blockMap[linkTarget][link.Block] {
+		log.WithFields(log.Fields{
+			"link-title":  title,
+			"link-target": link.Target,
+			"link-block":  link.Block,
+			"note":        note.Title,
+		}).Warn("missing block in target note")
+	}
 
-		return fmt.Sprintf("[%s](/%s/%s)", title, c.SubPath, target)
-	})
-	buf.WriteString(content)
+	if link.Embed {
+		if embeddedNote, ok := c.notes[linkTarget]; ok {
+			return c.embedNote(embeddedNote, linkTarget, link, anchor, embedded)
+		}
+		return c.embedFile(linkTarget, link), false
+	}
 
-	return buf.Bytes(), nil
+	return fmt.Sprintf("[%s](%s)", title, c.linkURL(linkTarget, anchor)), false
+}
+
+// resolveLink returns the Hugo compatible web link of the note or file, that the Obsidian link in the note located at
+// target points to. Relative links (`../Some Note`) resolve from the directory of the note, links with path
+// (`Directory/Some Note`) resolve to the note with that path in the vault and bare links (`Some Note`) prefer notes in
+// the same directory, before falling back to the shortest path in the vault.
+func (c Converter) resolveLink(target, link string) (string, bool) {
+	return resolveVaultLink(c.linkMap, c.pathMap, path.Dir(c.vaultPaths[target]), link)
+}
+
+// resolveFilteredLink is like resolveLink, but returns the (not converted) Hugo compatible web link of the note, that
+// was rejected by the filter
+func (c Converter) resolveFilteredLink(target, link string) (string, bool) {
+	return resolveVaultLink(c.filteredLinkMap, c.filteredPathMap, path.Dir(c.vaultPaths[target]), link)
+}
+
+// resolveVaultLink resolves the Obsidian link from a note in the vault directory with the given link and path maps
+func resolveVaultLink(linkMap, pathMap map[string]string, directory, link string) (string, bool) {
+	link = strings.TrimSuffix(link, ".md")
+	if strings.HasPrefix(link, "./") || strings.HasPrefix(link, "../") {
+		resolved, ok := pathMap[path.Join(directory, link)]
+		return resolved, ok
+	}
+
+	link = strings.TrimPrefix(link, "/")
+	if !strings.Contains(link, "/") {
+		if resolved, ok := pathMap[path.Join(directory, link)]; ok {
+			return resolved, true
+		}
+	} else if resolved, ok := pathMap[link]; ok {
+		return resolved, true
+	}
 
+	resolved, ok := linkMap[link]
+	return resolved, ok
 }
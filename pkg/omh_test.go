@@ -40,6 +40,113 @@ func TestConverter_Run(t *testing.T) {
 	)
 }
 
+func TestConverter_Run_PathLinks(t *testing.T) {
+	pages := convertVault(t, map[string]string{
+		"Overview.md":                  note("Root overview"),
+		"Projects/Overview.md":         note("Projects overview"),
+		"Projects/Alpha/Overview.md":   note("Alpha overview"),
+		"Projects/Alpha/Linking.md":    note("[[Overview]], [[../Overview]], [[./Overview]] and [[Projects/Alpha/Overview]]"),
+		"Projects/Beta/Linking.md":     note("[[Overview]], [[Alpha/Overview]], [[/Overview]] and [[../Alpha/Overview.md]]"),
+		"Projects/Beta/Static.txt":     "",
+		"Projects/Gamma/Linking Up.md": note("[[../Beta/Static.txt]] and [[Missing/Overview]]"),
+	}, nil)
+
+	assert.Equal(t, "[Overview](/sub-path/projects/alpha/overview/), [../Overview](/sub-path/projects/overview/), "+
+		"[./Overview](/sub-path/projects/alpha/overview/) and [Projects/Alpha/Overview](/sub-path/projects/alpha/overview/)",
+		pages["projects/alpha/linking.md"])
+	assert.Equal(t, "[Overview](/sub-path/overview/), [Alpha/Overview](/sub-path/projects/alpha/overview/), "+
+		"[/Overview](/sub-path/overview/) and [../Alpha/Overview.md](/sub-path/projects/alpha/overview/)",
+		pages["projects/beta/linking.md"])
+	assert.Equal(t, "[../Beta/Static.txt](/sub-path/projects/beta/static.txt) and Missing/Overview",
+		pages["projects/gamma/linking-up.md"])
+}
+
+func TestConverter_Run_AliasLinks(t *testing.T) {
+	pages := convertVault(t, map[string]string{
+		"Tech/Kubernetes.md": "---\naliases: [K8s, Kube]\n---\n\nKubernetes",
+		"Linking.md":         note("[[K8s]], [[Kube|cluster]] and [[K8s#Setup]]"),
+	}, nil)
+
+	assert.Equal(t, "[K8s](/sub-path/tech/kubernetes/), [cluster](/sub-path/tech/kubernetes/) and "+
+		"[K8s > Setup](/sub-path/tech/kubernetes/#setup)", pages["linking.md"])
+}
+
+func TestConverter_Run_HugoAliases(t *testing.T) {
+	notes := map[string]string{
+		"Tech/Kubernetes.md": "---\naliases: [K8s, Kubernetes, Kube Cluster]\n---\n\nKubernetes",
+	}
+
+	pages := convertVaultPages(t, notes, nil)
+	assert.Equal(t, "---\ntitle: Kubernetes\n---\n\n\nKubernetes", pages["tech/kubernetes.md"])
+
+	pages = convertVaultPages(t, notes, func(converter *omh.Converter) {
+		converter.HugoAliases = true
+	})
+	assert.Equal(t, "---\naliases:\n- /sub-path/tech/k-8-s/\n- /sub-path/tech/kube-cluster/\ntitle: Kubernetes\n---\n\n\nKubernetes",
+		pages["tech/kubernetes.md"])
+}
+
+// convertVault converts a temporary vault, made from the notes (`{"path/to/Note.md": "content"}`), and returns the
+// bodies of the resulting Hugo pages (`{"path/to/note.md": "body"}`)
+func convertVault(t *testing.T, notes map[string]string, configure func(converter *omh.Converter)) map[string]string {
+	pages := convertVaultPages(t, notes, configure)
+	for file, page := range pages {
+		pages[file] = page[strings.Index(page, "\n---\n")+7:]
+	}
+	return pages
+}
+
+// convertVaultPages is like convertVault, but returns the whole Hugo pages, including front matter
+func convertVaultPages(t *testing.T, notes map[string]string, configure func(converter *omh.Converter)) map[string]string {
+	pages, err := runVault(t, notes, nil, configure)
+	require.NoError(t, err)
+	return pages
+}
+
+// runVault converts a temporary vault, made from the notes that pass the filter, and returns the whole Hugo pages or
+// the error of the conversion
+func runVault(t *testing.T, notes map[string]string, filter omh.ObsidianFilter, configure func(converter *omh.Converter)) (map[string]string, error) {
+	output := t.TempDir()
+	root, err := omh.LoadObsidianDirectory(writeVault(t, notes), filter, true)
+	require.NoError(t, err)
+
+	converter := omh.Converter{
+		ConvertName:  strcase.ToKebab,
+		ObsidianRoot: root,
+		HugoRoot:     output,
+		SubPath:      "sub-path",
+	}
+	if configure != nil {
+		configure(&converter)
+	}
+	if err := converter.Run(); err != nil {
+		return nil, err
+	}
+
+	content := filepath.Join(output, "content", "sub-path") + string(filepath.Separator)
+	pages := make(map[string]string)
+	for file, page := range stripMap(content, loadDir(t, content)) {
+		pages[filepath.ToSlash(file)] = page
+	}
+	return pages, nil
+}
+
+// writeVault writes the notes (`{"path/to/Note.md": "content"}`) into a temporary vault and returns it's path
+func writeVault(t *testing.T, notes map[string]string) string {
+	source := t.TempDir()
+	for file, content := range notes {
+		fp := filepath.Join(source, filepath.FromSlash(file))
+		require.NoError(t, os.MkdirAll(filepath.Dir(fp), 0755))
+		require.NoError(t, ioutil.WriteFile(fp, []byte(content), 0644))
+	}
+	return source
+}
+
+// note returns the content of an Obsidian note with minimal front matter
+func note(lines ...string) string {
+	return "---\ntags: [any]\n---\n\n" + strings.Join(lines, "\n")
+}
+
 func loadDir(t *testing.T, path string) map[string]string {
 
 	files := make(map[string]string)
@@ -0,0 +1,120 @@
+package omh
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Report describes the outcome of a conversion, with all paths in slash notation
+type Report struct {
+
+	// Notes are the converted notes, with the path of the resulting Hugo page
+	Notes []ReportEntry `json:"notes"`
+
+	// Files are the copied static files, with the path of the copy
+	Files []ReportEntry `json:"files"`
+
+	// Skipped are the Markdown files, that were not converted for missing front matter
+	Skipped []ReportEntry `json:"skipped"`
+
+	// WithoutFrontMatter are the Markdown files, that were converted despite missing front matter
+	WithoutFrontMatter []ReportEntry `json:"without_front_matter"`
+
+	// Filtered are the notes, that were rejected by the filter
+	Filtered []ReportEntry `json:"filtered"`
+
+	// Problems are the unresolved links and other warnings, as reported in strict mode
+	Problems []Problem `json:"problems"`
+}
+
+// ReportEntry is a note or file in the Report
+type ReportEntry struct {
+
+	// Source is the path of the note or file in the vault
+	Source string `json:"source"`
+
+	// Output is the path of the written page or file, relative to the Hugo root
+	Output string `json:"output,omitempty"`
+
+	// Reason is why the note was not converted
+	Reason string `json:"reason,omitempty"`
+}
+
+// Summary returns a human readable summary of the report
+func (report Report) Summary() string {
+	lines := []string{fmt.Sprintf(
+		"converted %d notes (%d without front matter), copied %d files, skipped %d notes without front matter, filtered %d notes, found %d problems",
+		len(report.Notes), len(report.WithoutFrontMatter), len(report.Files), len(report.Skipped), len(report.Filtered),
+		len(report.Problems),
+	)}
+	for _, problem := range report.Problems {
+		lines = append(lines, "  "+problem.String())
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// newReport returns a report, that contains the skipped and filtered notes of the vault
+func (c Converter) newReport() *Report {
+	report := &Report{
+		Notes:    make([]ReportEntry, 0),
+		Files:    make([]ReportEntry, 0),
+		Skipped:  make([]ReportEntry, 0),
+		Filtered: make([]ReportEntry, 0),
+		Problems: make([]Problem, 0),
+
+		WithoutFrontMatter: make([]ReportEntry, 0),
+	}
+	c.ObsidianRoot.walk(c.ConvertName, "", "", func(dir ObsidianDirectory, vaultPrefix, targetPrefix string) {
+		for _, file := range dir.Skipped {
+			report.Skipped = append(report.Skipped, ReportEntry{
+				Source: path.Join(vaultPrefix, file),
+				Reason: "missing front matter",
+			})
+		}
+		for _, file := range dir.WithoutFrontMatter {
+			report.WithoutFrontMatter = append(report.WithoutFrontMatter, ReportEntry{
+				Source: path.Join(vaultPrefix, file),
+				Reason: "missing front matter, dated by file modification",
+			})
+		}
+		for _, note := range dir.Filtered {
+			reason := dir.FilterReasons[note.Title]
+			if reason == "" {
+				reason = "rejected by filter"
+			}
+			report.Filtered = append(report.Filtered, ReportEntry{
+				Source: path.Join(vaultPrefix, note.Title) + ".md",
+				Reason: reason,
+			})
+		}
+	})
+	return report
+}
+
+// vaultFile returns the path of the file in the vault
+func (c Converter) vaultFile(file string) string {
+	if rel, err := filepath.Rel(c.ObsidianRoot.Path, file); err == nil {
+		file = rel
+	}
+	return filepath.ToSlash(file)
+}
+
+// reportPath returns the path of the written page or file relative to the Hugo root
+func (c Converter) reportPath(file string) string {
+	if rel, err := filepath.Rel(c.HugoRoot, file); err == nil {
+		file = rel
+	}
+	return filepath.ToSlash(file)
+}
+
+func (report Report) write(file string) error {
+	encoded, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, append(encoded, '\n'), 0644)
+}
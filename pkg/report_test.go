@@ -0,0 +1,101 @@
+package omh_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/iancoleman/strcase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	omh "github.com/ukautz/obsidian-meets-hugo/pkg"
+)
+
+func TestConverter_Run_Report(t *testing.T) {
+	source := writeVault(t, map[string]string{
+		"Index.md":          note("Links to [[Missing]] and ![[Diagram.png]]"),
+		"Sub/Private.md":    "---\nprivate: true\n---\n\nSecret",
+		"Sub/Draft.md":      "No front matter",
+		"Sub/Diagram.png":   "png",
+		"Sub/Other Note.md": note("Some content"),
+	})
+	root, err := omh.LoadObsidianDirectory(source, func(note omh.ObsidianNote) bool {
+		return !note.Has("private")
+	}, true)
+	require.NoError(t, err)
+
+	output := t.TempDir()
+	reportFile := filepath.Join(t.TempDir(), "report.json")
+	converter := omh.Converter{
+		ConvertName:  strcase.ToKebab,
+		ObsidianRoot: root,
+		HugoRoot:     output,
+		SubPath:      "sub-path",
+		ReportFile:   reportFile,
+	}
+	require.NoError(t, converter.Run())
+
+	expect := omh.Report{
+		Notes: []omh.ReportEntry{
+			{Source: "Index.md", Output: "content/sub-path/index.md"},
+			{Source: "Sub/Other Note.md", Output: "content/sub-path/sub/other-note.md"},
+		},
+		Files: []omh.ReportEntry{
+			{Source: "Sub/Diagram.png", Output: "static/sub-path/sub/diagram.png"},
+		},
+		Skipped: []omh.ReportEntry{
+			{Source: "Sub/Draft.md", Reason: "missing front matter"},
+		},
+		Filtered: []omh.ReportEntry{
+			{Source: "Sub/Private.md", Reason: "rejected by filter"},
+		},
+		Problems: []omh.Problem{
+			{File: "Index.md", Line: 5, Message: "missing target for link [[Missing]]"},
+		},
+		WithoutFrontMatter: []omh.ReportEntry{},
+	}
+	assert.Equal(t, expect, converter.Report())
+
+	raw, err := ioutil.ReadFile(reportFile)
+	require.NoError(t, err)
+	var written omh.Report
+	require.NoError(t, json.Unmarshal(raw, &written))
+	assert.Equal(t, expect, written)
+
+	assert.Equal(t, "converted 2 notes (0 without front matter), copied 1 files, skipped 1 notes without front matter, filtered 1 notes, found 1 problems\n"+
+		"  Index.md:5: missing target for link [[Missing]]\n", converter.Report().Summary())
+}
+
+func TestConverter_Run_Report_WithoutFrontMatter(t *testing.T) {
+	source := writeVault(t, map[string]string{
+		"Index.md": note("Links to [[Draft]]"),
+		"Draft.md": "No front matter",
+	})
+	root, err := omh.ObsidianLoader{WithoutFrontMatter: true}.Load(source)
+	require.NoError(t, err)
+
+	output := t.TempDir()
+	converter := omh.Converter{
+		ConvertName:  strcase.ToKebab,
+		ObsidianRoot: root,
+		HugoRoot:     output,
+		SubPath:      "sub-path",
+	}
+	require.NoError(t, converter.Run())
+
+	report := converter.Report()
+	assert.Equal(t, []omh.ReportEntry{
+		{Source: "Draft.md", Output: "content/sub-path/draft.md"},
+		{Source: "Index.md", Output: "content/sub-path/index.md"},
+	}, report.Notes)
+	assert.Equal(t, []omh.ReportEntry{
+		{Source: "Draft.md", Reason: "missing front matter, dated by file modification"},
+	}, report.WithoutFrontMatter)
+	assert.Empty(t, report.Skipped)
+	assert.Empty(t, report.Problems)
+
+	index, err := ioutil.ReadFile(filepath.Join(output, "content", "sub-path", "index.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(index), "[Draft](/sub-path/draft/)")
+}
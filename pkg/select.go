@@ -0,0 +1,422 @@
+package omh
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Selection is a compiled boolean expression, that selects notes, like
+//
+//	tags has "public" and not (status == "draft") and folder under "Projects" and date >= 2023-01-01
+//
+// Comparisons have the form `<field> <operator> <value>` or `<field> exists` and can be combined with `and`, `or`,
+// `not` and parentheses. Fields are `title`, `folder` (the path of the directory of the note in the vault), `tags`
+// (from front matter and inline), `date` (as rendered in Hugo) or any other front matter key, which must be quoted with
+// backticks if it contains spaces, like `date created`. Values are double quoted strings or bare words, like numbers
+// and dates. Operators are:
+//
+//	==, !=          equality, of any element for lists
+//	<, <=, >, >=    order, of dates, numbers or strings (in that order of preference)
+//	has             list contains value, with nested tags matching their ancestors
+//	under           path is value or nested below it
+//	matches         glob pattern, like "Meeting *"
+type Selection struct {
+	root selectionNode
+}
+
+type selectionNode interface {
+	matches(note ObsidianNote, folder string) bool
+}
+
+type selectionAnd []selectionNode
+
+func (and selectionAnd) matches(note ObsidianNote, folder string) bool {
+	for _, node := range and {
+		if !node.matches(note, folder) {
+			return false
+		}
+	}
+	return true
+}
+
+type selectionOr []selectionNode
+
+func (or selectionOr) matches(note ObsidianNote, folder string) bool {
+	for _, node := range or {
+		if node.matches(note, folder) {
+			return true
+		}
+	}
+	return false
+}
+
+type selectionNot struct {
+	node selectionNode
+}
+
+func (not selectionNot) matches(note ObsidianNote, folder string) bool {
+	return !not.node.matches(note, folder)
+}
+
+type selectionComparison struct {
+	field    string
+	operator string
+	value    string
+}
+
+func (comparison selectionComparison) matches(note ObsidianNote, folder string) bool {
+	values, ok := selectionField(note, folder, comparison.field)
+	if comparison.operator == "exists" {
+		return ok
+	} else if !ok {
+		return comparison.operator == "!="
+	}
+
+	switch comparison.operator {
+	case "!=":
+		for _, value := range values {
+			if value == comparison.value {
+				return false
+			}
+		}
+		return true
+	case "has":
+		for _, value := range values {
+			if value == comparison.value || (comparison.field == "tags" && TagMatches(value, comparison.value)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, value := range values {
+		switch comparison.operator {
+		case "==":
+			if value == comparison.value {
+				return true
+			}
+		case "under":
+			if value == comparison.value || strings.HasPrefix(value, strings.TrimSuffix(comparison.value, "/")+"/") ||
+				comparison.value == "" || comparison.value == "/" {
+				return true
+			}
+		case "matches":
+			if matched, _ := path.Match(comparison.value, value); matched {
+				return true
+			}
+		default:
+			if compareSelectionValues(value, comparison.value, comparison.operator) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// selectionField returns the values of the field of the note, or false if the note does not have the field
+func selectionField(note ObsidianNote, folder, field string) ([]string, bool) {
+	switch field {
+	case "title":
+		return []string{note.Title}, true
+	case "folder":
+		return []string{folder}, true
+	case "tags":
+		tags := note.Tags()
+		return tags, len(tags) > 0
+	case "date":
+		if date, ok := note.FrontMatter["date"].(time.Time); ok {
+			return []string{date.Format(time.RFC3339)}, true
+		} else if note.Has("date") {
+			return []string{note.String("date")}, true
+		}
+		date, err := note.extractDate()
+		if err != nil || date == nil {
+			return nil, false
+		}
+		return []string{date.Format(time.RFC3339)}, true
+	}
+
+	if !note.Has(field) {
+		return nil, false
+	} else if values := note.Strings(field); values != nil {
+		return values, true
+	} else if date, ok := note.FrontMatter[field].(time.Time); ok {
+		return []string{date.Format(time.RFC3339)}, true
+	}
+	return []string{note.String(field)}, true
+}
+
+// compareSelectionValues compares the value to the reference with the (order) operator, as dates, if both are dates,
+// or numbers, if both are numbers, or strings otherwise
+func compareSelectionValues(value, reference, operator string) bool {
+	result := strings.Compare(value, reference)
+	if valueDate, err := parseSelectionDate(value); err == nil {
+		if referenceDate, err := parseSelectionDate(reference); err == nil {
+			result = 0
+			if valueDate.Before(referenceDate) {
+				result = -1
+			} else if valueDate.After(referenceDate) {
+				result = 1
+			}
+		}
+	} else if valueNumber, err := strconv.ParseFloat(value, 64); err == nil {
+		if referenceNumber, err := strconv.ParseFloat(reference, 64); err == nil {
+			result = 0
+			if valueNumber < referenceNumber {
+				result = -1
+			} else if valueNumber > referenceNumber {
+				result = 1
+			}
+		}
+	}
+
+	switch operator {
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	default:
+		return result >= 0
+	}
+}
+
+func parseSelectionDate(value string) (time.Time, error) {
+	for _, format := range obsidianDateFormats {
+		if date, err := time.ParseInLocation(format, value, TimeZone); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported date `%s`", value)
+}
+
+// ParseSelection compiles the expression (see Selection) or returns an error, if the expression is invalid
+func ParseSelection(expression string) (*Selection, error) {
+	tokens, err := tokenizeSelection(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &selectionParser{tokens: tokens}
+	root, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	} else if parser.pos < len(parser.tokens) {
+		return nil, parser.unexpected()
+	}
+
+	return &Selection{root: root}, nil
+}
+
+// Matches returns whether the note, located in the folder (path of the directory in the vault), is selected
+func (selection *Selection) Matches(note ObsidianNote, folder string) bool {
+	return selection.root.matches(note, folder)
+}
+
+// Filter returns the filter, that accepts the selected notes of the vault located at root, for LoadObsidianDirectory
+func (selection *Selection) Filter(root string) ObsidianFilter {
+	return func(note ObsidianNote) bool {
+		folder := ""
+		if note.Directory != nil {
+			if rel, err := filepath.Rel(root, note.Directory.Path); err == nil && rel != "." {
+				folder = filepath.ToSlash(rel)
+			}
+		}
+		return selection.Matches(note, folder)
+	}
+}
+
+// selectionToken is a token of a selection expression
+type selectionToken struct {
+	text   string
+	quoted bool
+	pos    int
+}
+
+// selectionOperators are the comparison operators, longest first
+var selectionOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func tokenizeSelection(expression string) ([]selectionToken, error) {
+	tokens := make([]selectionToken, 0)
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(' || r == ')':
+			tokens = append(tokens, selectionToken{text: string(r), pos: i})
+			i++
+
+		case r == '"' || r == '`':
+			var text strings.Builder
+			start := i
+			for i++; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && r == '"' && i+1 < len(runes) {
+					i++
+				}
+				text.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated quote at position %d", start)
+			}
+			tokens = append(tokens, selectionToken{text: text.String(), quoted: true, pos: start})
+			i++
+
+		default:
+			operator := ""
+			for _, candidate := range selectionOperators {
+				if strings.HasPrefix(string(runes[i:]), candidate) {
+					operator = candidate
+					break
+				}
+			}
+			if operator != "" {
+				tokens = append(tokens, selectionToken{text: operator, pos: i})
+				i += len(operator)
+				continue
+			}
+
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(`()"`+"`=!<>", runes[i]) {
+				i++
+			}
+			if start == i {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+			}
+			tokens = append(tokens, selectionToken{text: string(runes[start:i]), pos: start})
+		}
+	}
+	return tokens, nil
+}
+
+// selectionParser is a recursive descent parser of the grammar:
+//
+//	or         = and { "or" and }
+//	and        = unary { "and" unary }
+//	unary      = "not" unary | "(" or ")" | comparison
+//	comparison = field "exists" | field operator value
+type selectionParser struct {
+	tokens []selectionToken
+	pos    int
+}
+
+func (parser *selectionParser) parseOr() (selectionNode, error) {
+	return parser.parseList("or", parser.parseAnd, func(nodes []selectionNode) selectionNode {
+		return selectionOr(nodes)
+	})
+}
+
+func (parser *selectionParser) parseAnd() (selectionNode, error) {
+	return parser.parseList("and", parser.parseUnary, func(nodes []selectionNode) selectionNode {
+		return selectionAnd(nodes)
+	})
+}
+
+func (parser *selectionParser) parseList(keyword string, parse func() (selectionNode, error), combine func([]selectionNode) selectionNode) (selectionNode, error) {
+	node, err := parse()
+	if err != nil {
+		return nil, err
+	}
+	nodes := []selectionNode{node}
+	for parser.keyword(keyword) {
+		parser.pos++
+		if node, err = parse(); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return combine(nodes), nil
+}
+
+func (parser *selectionParser) parseUnary() (selectionNode, error) {
+	if parser.keyword("not") {
+		parser.pos++
+		node, err := parser.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return selectionNot{node: node}, nil
+	}
+
+	if parser.keyword("(") {
+		parser.pos++
+		node, err := parser.parseOr()
+		if err != nil {
+			return nil, err
+		} else if !parser.keyword(")") {
+			return nil, parser.unexpected()
+		}
+		parser.pos++
+		return node, nil
+	}
+
+	return parser.parseComparison()
+}
+
+func (parser *selectionParser) parseComparison() (selectionNode, error) {
+	if parser.pos+1 >= len(parser.tokens) {
+		return nil, parser.unexpected()
+	}
+	field, operator := parser.tokens[parser.pos], parser.tokens[parser.pos+1]
+	if !field.quoted && isSelectionKeyword(field.text) {
+		return nil, parser.unexpected()
+	}
+	parser.pos += 2
+
+	switch operator.text {
+	case "exists":
+		return selectionComparison{field: field.text, operator: operator.text}, nil
+	case "==", "!=", "<", "<=", ">", ">=", "has", "under", "matches":
+	default:
+		parser.pos--
+		return nil, parser.unexpected()
+	}
+
+	if parser.pos >= len(parser.tokens) {
+		return nil, parser.unexpected()
+	}
+	value := parser.tokens[parser.pos]
+	if !value.quoted && isSelectionKeyword(value.text) {
+		return nil, parser.unexpected()
+	}
+	parser.pos++
+
+	return selectionComparison{field: field.text, operator: operator.text, value: value.text}, nil
+}
+
+// keyword returns whether the current token is the (unquoted) keyword
+func (parser *selectionParser) keyword(keyword string) bool {
+	return parser.pos < len(parser.tokens) && !parser.tokens[parser.pos].quoted && parser.tokens[parser.pos].text == keyword
+}
+
+func (parser *selectionParser) unexpected() error {
+	if parser.pos >= len(parser.tokens) {
+		return fmt.Errorf("unexpected end of selection")
+	}
+	token := parser.tokens[parser.pos]
+	return fmt.Errorf("unexpected %q at position %d", token.text, token.pos)
+}
+
+func isSelectionKeyword(text string) bool {
+	switch text {
+	case "and", "or", "not", "(", ")":
+		return true
+	}
+	for _, operator := range selectionOperators {
+		if text == operator {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package omh_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	omh "github.com/ukautz/obsidian-meets-hugo/pkg"
+)
+
+func TestSelection_Matches(t *testing.T) {
+	notes := map[string]omh.ObsidianNote{
+		"Projects/Alpha": {
+			Title:       "Alpha",
+			FrontMatter: omh.FrontMatter{"tags": []interface{}{"public", "project/alpha"}, "status": "done", "date created": "2023-03-01"},
+		},
+		"Projects/Beta/Beta Draft": {
+			Title:       "Beta Draft",
+			FrontMatter: omh.FrontMatter{"tags": []interface{}{"public"}, "status": "draft", "priority": 10},
+			Content:     "Some #project/beta tag",
+		},
+		"Meeting 2022": {
+			Title:       "Meeting 2022",
+			FrontMatter: omh.FrontMatter{"tags": "private", "date": "2022-12-24"},
+		},
+	}
+
+	tests := map[string][]string{
+		`tags has "public"`: {"Projects/Alpha", "Projects/Beta/Beta Draft"},
+		`tags has project`:  {"Projects/Alpha", "Projects/Beta/Beta Draft"},
+		`tags has "project/beta" or tags has "private"`: {"Projects/Beta/Beta Draft", "Meeting 2022"},
+		`not (status == "draft")`:                       {"Projects/Alpha", "Meeting 2022"},
+		`status != draft`:                               {"Projects/Alpha", "Meeting 2022"},
+		`status exists and not status == "done"`:        {"Projects/Beta/Beta Draft"},
+		`folder under "Projects"`:                       {"Projects/Alpha", "Projects/Beta/Beta Draft"},
+		`folder under "Projects/Beta"`:                  {"Projects/Beta/Beta Draft"},
+		`folder == ""`:                                  {"Meeting 2022"},
+		`title matches "Meeting *"`:                     {"Meeting 2022"},
+		`date >= 2023-01-01`:                            {"Projects/Alpha"},
+		`date < "2023-01-01"`:                           {"Meeting 2022"},
+		"`date created` <= 2023-03-01":                  {"Projects/Alpha"},
+		`priority > 9 and priority < 11`:                {"Projects/Beta/Beta Draft"},
+		`tags has "public" and not (status == "draft") and folder under "Projects" and date >= 2023-01-01`: {
+			"Projects/Alpha",
+		},
+	}
+
+	for expression, expect := range tests {
+		expression, expect := expression, expect
+		t.Run(expression, func(t *testing.T) {
+			selection, err := omh.ParseSelection(expression)
+			require.NoError(t, err)
+
+			selected := make([]string, 0)
+			for _, name := range []string{"Projects/Alpha", "Projects/Beta/Beta Draft", "Meeting 2022"} {
+				folder := filepath.ToSlash(filepath.Dir(name))
+				if folder == "." {
+					folder = ""
+				}
+				if selection.Matches(notes[name], folder) {
+					selected = append(selected, name)
+				}
+			}
+			assert.Equal(t, expect, selected)
+		})
+	}
+}
+
+func TestParseSelection_Errors(t *testing.T) {
+	for expression, expect := range map[string]string{
+		``:                           "unexpected end of selection",
+		`tags has`:                   "unexpected end of selection",
+		`tags contains "x"`:          `unexpected "contains" at position 5`,
+		`(tags has "x"`:              "unexpected end of selection",
+		`tags has "x")`:              `unexpected ")" at position 12`,
+		`tags has "x" and or`:        `unexpected "or" at position 17`,
+		`title == "unterminated`:     "unterminated quote at position 9",
+		`tags has "x" status == "y"`: `unexpected "status" at position 13`,
+	} {
+		_, err := omh.ParseSelection(expression)
+		if assert.Error(t, err, expression) {
+			assert.Equal(t, expect, err.Error(), expression)
+		}
+	}
+}
+
+func TestSelection_Filter(t *testing.T) {
+	source := writeVault(t, map[string]string{
+		"Index.md":          note("Index"),
+		"Projects/Alpha.md": note("Alpha"),
+		"Archive/Old.md":    note("Old"),
+	})
+	selection, err := omh.ParseSelection(`folder under Projects or title == Index`)
+	require.NoError(t, err)
+
+	root, err := omh.LoadObsidianDirectory(source, selection.Filter(source), true)
+	require.NoError(t, err)
+	require.Len(t, root.Notes, 1)
+	assert.Equal(t, "Index", root.Notes[0].Title)
+	require.Len(t, root.Childs, 2)
+	assert.Equal(t, "Old", root.Childs[0].Filtered[0].Title)
+	assert.Equal(t, "Alpha", root.Childs[1].Notes[0].Title)
+}
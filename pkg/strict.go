@@ -0,0 +1,129 @@
+package omh
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Problem is an issue in a note of the vault, that is reported in strict mode and in the Report
+type Problem struct {
+
+	// File is the path of the note in the vault
+	File string `json:"file"`
+
+	// Line is the line number within the file, or 0 if the problem concerns the whole file
+	Line int `json:"line,omitempty"`
+
+	// Message describes the problem
+	Message string `json:"message"`
+}
+
+func (problem Problem) String() string {
+	if problem.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", problem.File, problem.Line, problem.Message)
+	}
+	return fmt.Sprintf("%s: %s", problem.File, problem.Message)
+}
+
+// StrictError is returned by Converter.Run in strict mode and contains all problems found in the vault
+type StrictError struct {
+	Problems []Problem
+}
+
+func (err *StrictError) Error() string {
+	lines := []string{fmt.Sprintf("found %d problems in vault:", len(err.Problems))}
+	for _, problem := range err.Problems {
+		lines = append(lines, "  "+problem.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// filteredLinkProblem is the message prefix of problems of links to filtered notes with FilteredLinkFail
+const filteredLinkProblem = "link to filtered note "
+
+// problems returns all unresolved links, links to filtered notes with FilteredLinkFail, missing embeds, unparsable dates
+// and duplicate titles of the notes in the vault, ordered by file and line
+func (c Converter) problems() []Problem {
+	problems := make([]Problem, 0)
+	titles := make(map[string][]string)
+	c.ObsidianRoot.walkNotes(c.ConvertName, func(note ObsidianNote, vaultPath, target string) {
+		file := vaultPath + ".md"
+		titles[note.Title] = append(titles[note.Title], vaultPath)
+
+		if _, ok := c.FrontMatter["date"]; !ok && !note.Has("date") {
+			if _, err := note.extractDate(); err != nil {
+				problem := Problem{File: file, Message: err.Error()}
+				for _, key := range obsidianDateKeys {
+					if note.Has(key) {
+						problem.Line = note.FrontMatterLines[key]
+						break
+					}
+				}
+				problems = append(problems, problem)
+			}
+		}
+
+		for _, link := range c.scanLinks(note, target) {
+			problem := Problem{File: file, Line: link.Line}
+			if note.ContentLine > 0 {
+				problem.Line += note.ContentLine - 1
+			}
+			switch {
+			case link.Filtered && c.FilteredLinks == FilteredLinkFail:
+				problem.Message = filteredLinkProblem + link.Raw
+			case link.Filtered:
+				continue
+			case link.Target == "" && link.Embed:
+				problem.Message = "missing target for embed " + link.Raw
+			case link.Target == "":
+				problem.Message = "missing target for link " + link.Raw
+			case link.Block != "" && !c.blockMap[link.Target][link.Block]:
+				problem.Message = "missing block in target note for link " + link.Raw
+			case link.Embed && link.Heading != "" && strings.HasSuffix(link.Target, "/") && c.EmbedMode != EmbedShortcode:
+				if _, ok := noteSection(c.notes[link.Target].Content, link.Heading, c.AnchorStyle); ok {
+					continue
+				}
+				problem.Message = "missing section to embed " + link.Raw
+			default:
+				continue
+			}
+			problems = append(problems, problem)
+		}
+	})
+
+	// notes with the same title can only be linked with their path, bare links resolve to the note in the same
+	// directory as the linking note, otherwise to the note with the shortest path
+	for title, vaultPaths := range titles {
+		if len(vaultPaths) < 2 {
+			continue
+		}
+		sortVaultPaths(vaultPaths)
+		for _, shadowed := range vaultPaths[1:] {
+			problems = append(problems, Problem{
+				File:    shadowed + ".md",
+				Message: fmt.Sprintf("duplicate title %q, bare links resolve to the note in the same directory, otherwise to %s.md", title, vaultPaths[0]),
+			})
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].File != problems[j].File {
+			return problems[i].File < problems[j].File
+		}
+		return problems[i].Line < problems[j].Line
+	})
+
+	return problems
+}
+
+// filteredLinkProblems returns the problems of links to filtered notes
+func filteredLinkProblems(problems []Problem) []Problem {
+	filtered := make([]Problem, 0)
+	for _, problem := range problems {
+		if strings.HasPrefix(problem.Message, filteredLinkProblem) {
+			filtered = append(filtered, problem)
+		}
+	}
+	return filtered
+}
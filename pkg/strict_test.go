@@ -0,0 +1,84 @@
+package omh_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	omh "github.com/ukautz/obsidian-meets-hugo/pkg"
+)
+
+func TestConverter_Run_Strict(t *testing.T) {
+	notes := map[string]string{
+		"Index.md": note(
+			"Links to [[Missing Note]] and [[Topic#^nope]].",
+			"",
+			"```",
+			"[[Ignored In Code]]",
+			"```",
+			"",
+			"![[Missing Image.png]] and [broken](Gone.md) and [[Topic]]",
+			"![[Topic#No Such Heading]]",
+		),
+		"Topic.md":     "---\ntags: [any]\ndate created: yesterday\n---\n\nSome topic",
+		"Sub/Topic.md": note("Another topic"),
+	}
+
+	_, err := runVault(t, notes, nil, func(converter *omh.Converter) {
+		converter.Strict = true
+	})
+	require.Error(t, err)
+
+	var strict *omh.StrictError
+	require.True(t, errors.As(err, &strict))
+	assert.Equal(t, []omh.Problem{
+		{File: "Index.md", Line: 5, Message: "missing target for link [[Missing Note]]"},
+		{File: "Index.md", Line: 5, Message: "missing block in target note for link [[Topic#^nope]]"},
+		{File: "Index.md", Line: 11, Message: "missing target for embed ![[Missing Image.png]]"},
+		{File: "Index.md", Line: 11, Message: "missing target for link [broken](Gone.md)"},
+		{File: "Index.md", Line: 12, Message: "missing section to embed ![[Topic#No Such Heading]]"},
+		{File: "Sub/Topic.md", Message: `duplicate title "Topic", bare links resolve to the note in the same directory, otherwise to Topic.md`},
+		{File: "Topic.md", Line: 3, Message: "unsupported date `yesterday`"},
+	}, strict.Problems)
+	assert.Contains(t, err.Error(), "found 7 problems in vault:\n  Index.md:5: missing target for link [[Missing Note]]\n")
+}
+
+func TestConverter_Run_Strict_NoProblems(t *testing.T) {
+	pages, err := runVault(t, map[string]string{
+		"Index.md": note("Links to [[Topic]]"),
+		"Topic.md": note("Some topic ^block"),
+	}, nil, func(converter *omh.Converter) {
+		converter.Strict = true
+	})
+	require.NoError(t, err)
+	assert.Len(t, pages, 2)
+}
+
+func TestConverter_Run_Strict_WithoutFrontMatter(t *testing.T) {
+	source := writeVault(t, map[string]string{
+		"Quick.md": "\nLinks to [[Missing]]\n\n---\n\nAnd [[Gone]]\n",
+		"Noted.md": "---\n---\n\n[[Absent]]\n",
+		"Index.md": note("Links to [[Quick]]"),
+	})
+	root, err := omh.ObsidianLoader{WithoutFrontMatter: true}.Load(source)
+	require.NoError(t, err)
+
+	converter := omh.Converter{
+		ConvertName:  strings.ToLower,
+		ObsidianRoot: root,
+		HugoRoot:     t.TempDir(),
+		Strict:       true,
+	}
+	err = converter.Run()
+	require.Error(t, err)
+
+	var strict *omh.StrictError
+	require.True(t, errors.As(err, &strict))
+	assert.Equal(t, []omh.Problem{
+		{File: "Noted.md", Line: 4, Message: "missing target for link [[Absent]]"},
+		{File: "Quick.md", Line: 2, Message: "missing target for link [[Missing]]"},
+		{File: "Quick.md", Line: 6, Message: "missing target for link [[Gone]]"},
+	}, strict.Problems)
+}
@@ -0,0 +1,170 @@
+package omh
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// obsidianTag matches inline tags, like `#project/alpha`, that are preceded by whitespace (so that anchors and URLs
+// are not matched) and contain at least one non-numerical character
+var obsidianTag = regexp.MustCompile(`(^|[ \t\n])#([\p{L}\p{N}_/-]*[\p{L}_/-][\p{L}\p{N}_/-]*)`)
+
+// Tags returns the tags of the note from the `tags` front matter, followed by the inline tags in the content (like
+// `#some-tag`), without leading `#` and without duplicates
+func (note ObsidianNote) Tags() []string {
+	tags := make([]string, 0)
+	seen := make(map[string]bool)
+	add := func(tag string) {
+		tag = strings.Trim(strings.TrimSpace(tag), "#/")
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	if matter := note.Strings("tags"); matter != nil {
+		for _, tag := range matter {
+			add(tag)
+		}
+	} else if matter := note.String("tags"); matter != "" {
+		for _, tag := range strings.FieldsFunc(matter, func(r rune) bool { return r == ',' || r == ' ' }) {
+			add(tag)
+		}
+	}
+	for _, tag := range inlineTags(note.Content) {
+		add(tag)
+	}
+
+	return tags
+}
+
+// TagMatches returns whether the tag is the filter tag or nested below it, like `project/alpha` for `project`. Tags are
+// compared case-insensitive, like in Obsidian.
+func TagMatches(tag, filter string) bool {
+	tags, filters := strings.Split(strings.Trim(tag, "#/"), "/"), strings.Split(strings.Trim(filter, "#/"), "/")
+	if len(tags) < len(filters) {
+		return false
+	}
+	for i, segment := range filters {
+		if !strings.EqualFold(tags[i], segment) {
+			return false
+		}
+	}
+	return true
+}
+
+// expandTags returns the tags with ConvertName applied to each level, preceded by all their ancestors, like `project`
+// and `project/alpha` for `project/alpha/backend`, without duplicates
+func (c Converter) expandTags(tags []string) []string {
+	expanded := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		segments := strings.Split(tag, "/")
+		for i := range segments {
+			segments[i] = c.ConvertName(segments[i])
+			if ancestor := strings.Join(segments[:i+1], "/"); !seen[ancestor] {
+				seen[ancestor] = true
+				expanded = append(expanded, ancestor)
+			}
+		}
+	}
+	return expanded
+}
+
+// inlineTags returns all inline tags in the content, ignoring code, comments, headings and links
+func inlineTags(content string) []string {
+	tags := make([]string, 0)
+	for _, segment := range scanMarkdown(content, true) {
+		if segment.Kind != markdownProse {
+			continue
+		}
+		mapLines(segment.Text, func(line string) string {
+			spans := linkSpans(line)
+			for _, loc := range obsidianTag.FindAllStringSubmatchIndex(line, -1) {
+				if !inSpans(spans, loc[4]) {
+					tags = append(tags, line[loc[4]:loc[5]])
+				}
+			}
+			return line
+		})
+	}
+	return tags
+}
+
+// convertTags rewrites all inline tags in prose, that are not in headings or links, into links to the Hugo taxonomy
+// term page
+func (c Converter) convertTags(prose string) string {
+	return mapLines(prose, func(line string) string {
+		var converted strings.Builder
+		last, spans := 0, linkSpans(line)
+		for _, loc := range obsidianTag.FindAllStringSubmatchIndex(line, -1) {
+			tag := strings.Trim(line[loc[4]:loc[5]], "/")
+			if tag == "" || inSpans(spans, loc[4]) {
+				continue
+			}
+			converted.WriteString(line[last:loc[0]])
+			converted.WriteString(fmt.Sprintf("%s[#%s](%s)", line[loc[2]:loc[3]], line[loc[4]:loc[5]], c.tagURL(tag)))
+			last = loc[1]
+		}
+		converted.WriteString(line[last:])
+		return converted.String()
+	})
+}
+
+// linkSpans returns the start and end positions of all wikilinks and Markdown links in the line
+func linkSpans(line string) [][]int {
+	return append(obsidianLink.FindAllStringIndex(line, -1), markdownLink.FindAllStringIndex(line, -1)...)
+}
+
+// inSpans returns whether the position is within any of the spans
+func inSpans(spans [][]int, pos int) bool {
+	for _, span := range spans {
+		if pos >= span[0] && pos < span[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// tagTerms returns the taxonomy terms of the tags, as written to the front matter, which are expanded, if ExpandTags is
+// enabled
+func (c Converter) tagTerms(tags []string) []string {
+	if c.ExpandTags {
+		return c.expandTags(tags)
+	}
+	return tags
+}
+
+// tagURL returns the URL of the Hugo taxonomy term page of the tag, for the term written to the front matter
+func (c Converter) tagURL(tag string) string {
+	taxonomy := c.TagsKey
+	if taxonomy == "" {
+		taxonomy = "tags"
+	}
+	terms := c.tagTerms([]string{tag})
+	return "/" + path.Join(taxonomy, hugoTermPath(terms[len(terms)-1])) + "/"
+}
+
+// hugoTermPath returns the path of the page, that Hugo generates for the taxonomy term, which is lower cased, with
+// spaces replaced by hyphens and URL encoded
+func hugoTermPath(term string) string {
+	segments := strings.Split(strings.ToLower(strings.Join(strings.Fields(term), "-")), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return path.Join(segments...)
+}
+
+// mapLines applies fn to all lines of the prose, that are not headings
+func mapLines(prose string, fn func(line string) string) string {
+	lines := strings.SplitAfter(prose, "\n")
+	for i, line := range lines {
+		if !markdownHeading.MatchString(strings.TrimRight(line, "\r\n")) {
+			lines[i] = fn(line)
+		}
+	}
+	return strings.Join(lines, "")
+}
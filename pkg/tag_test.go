@@ -0,0 +1,94 @@
+package omh_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	omh "github.com/ukautz/obsidian-meets-hugo/pkg"
+)
+
+func TestObsidianNote_Tags(t *testing.T) {
+	note := omh.ObsidianNote{
+		FrontMatter: omh.FrontMatter{"tags": []interface{}{"matter", "#hashed", "project/alpha"}},
+		Content: strings.Join([]string{
+			"#inline at start and #project/alpha again",
+			"# Heading with #heading-tag",
+			"Not a tag: https://example.com/#anchor, [link](#anchor), [[Note#Heading]], issue #123",
+			"`#code` and %% #comment %%",
+			"```",
+			"#fenced",
+			"```",
+			"Tags with #nested/deep/ and #ünicode",
+			"Not in links: [[Note| #fake]] or [see #fake](https://example.com/)",
+		}, "\n"),
+	}
+	assert.Equal(t, []string{"matter", "hashed", "project/alpha", "inline", "nested/deep", "ünicode"}, note.Tags())
+
+	assert.Equal(t, []string{"one", "two"}, omh.ObsidianNote{FrontMatter: omh.FrontMatter{"tags": "one, #two"}}.Tags())
+	assert.Empty(t, omh.ObsidianNote{}.Tags())
+}
+
+func TestConverter_Run_Tags(t *testing.T) {
+	notes := map[string]string{
+		"Index.md": "---\ntags: [matter]\n---\n\nSome #project/Alpha-Team text [[Index| #fake]]\n\n# Heading #not-linked",
+	}
+
+	t.Run("merged into taxonomy", func(t *testing.T) {
+		pages := convertVaultPages(t, notes, func(converter *omh.Converter) {
+			converter.TagsKey = "categories"
+		})
+		assert.Equal(t, "---\ncategories:\n- matter\n- project/Alpha-Team\ntitle: Index\n---\n\n\n"+
+			"Some #project/Alpha-Team text [ #fake](/sub-path/index/)\n\n# Heading #not-linked", pages["index.md"])
+	})
+
+	t.Run("tag links", func(t *testing.T) {
+		pages := convertVault(t, notes, func(converter *omh.Converter) {
+			converter.TagsKey = "categories"
+			converter.TagLinks = true
+		})
+		assert.Equal(t, "Some [#project/Alpha-Team](/categories/project/alpha-team/) text [ #fake](/sub-path/index/)\n\n"+
+			"# Heading #not-linked",
+			pages["index.md"])
+	})
+
+	t.Run("tag links like hugo terms", func(t *testing.T) {
+		for name, expand := range map[string]bool{"raw": false, "expanded": true} {
+			pages := convertVaultPages(t, map[string]string{
+				"Index.md": note("Some #MyTag and #1a and #ünicode"),
+			}, func(converter *omh.Converter) {
+				converter.TagLinks = true
+				converter.ExpandTags = expand
+			})
+			terms := "- any\n- MyTag\n- 1a\n- ünicode\n"
+			expect := "[#MyTag](/tags/mytag/) and [#1a](/tags/1a/) and [#ünicode](/tags/%C3%BCnicode/)"
+			if expand {
+				terms = "- any\n- my-tag\n- 1-a\n- ünicode\n"
+				expect = "[#MyTag](/tags/my-tag/) and [#1a](/tags/1-a/) and [#ünicode](/tags/%C3%BCnicode/)"
+			}
+			assert.Contains(t, pages["index.md"], terms, name)
+			assert.Contains(t, pages["index.md"], expect, name)
+		}
+	})
+}
+
+func TestTagMatches(t *testing.T) {
+	assert.True(t, omh.TagMatches("project", "project"))
+	assert.True(t, omh.TagMatches("project/alpha/backend", "project"))
+	assert.True(t, omh.TagMatches("project/alpha/backend", "#project/alpha/"))
+	assert.False(t, omh.TagMatches("projects", "project"))
+	assert.False(t, omh.TagMatches("project", "project/alpha"))
+	assert.True(t, omh.TagMatches("Project/Alpha", "project"))
+	assert.True(t, omh.TagMatches("project/alpha", "#PROJECT/Alpha"))
+	assert.False(t, omh.TagMatches("Projects", "project"))
+}
+
+func TestConverter_Run_ExpandTags(t *testing.T) {
+	pages := convertVaultPages(t, map[string]string{
+		"Index.md": "---\ntags: [Project/Alpha Team/backend, project/beta]\n---\n\nSome #Other text",
+	}, func(converter *omh.Converter) {
+		converter.ExpandTags = true
+	})
+	assert.Equal(t, "---\ntags:\n- project\n- project/alpha-team\n- project/alpha-team/backend\n- project/beta\n- other\n"+
+		"title: Index\n---\n\n\nSome #Other text", pages["index.md"])
+}
@@ -0,0 +1,72 @@
+package omh
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// InlineTransform rewrites Obsidian specific inline syntax in prose into Hugo compatible Markdown. Transforms are
+// never applied to code or comments.
+type InlineTransform func(prose string) string
+
+// obsidianHighlight matches highlighted text, like `==some text==`, that does not start or end with whitespace
+var obsidianHighlight = regexp.MustCompile(`==([^=\s](?:[^=\n]*[^=\s])?)==`)
+
+// HighlightTransform renders highlighted text (`==some text==`) as HTML `<mark>some text</mark>`, except within URLs,
+// link destinations and wikilinks
+func HighlightTransform(prose string) string {
+	var transformed strings.Builder
+	last, spans := 0, destinationSpans(prose)
+	for _, loc := range obsidianHighlight.FindAllStringSubmatchIndex(prose, -1) {
+		if overlapsSpans(spans, loc[0], loc[1]) {
+			continue
+		}
+		transformed.WriteString(prose[last:loc[0]])
+		transformed.WriteString("<mark>" + prose[loc[2]:loc[3]] + "</mark>")
+		last = loc[1]
+	}
+	transformed.WriteString(prose[last:])
+
+	return transformed.String()
+}
+
+// overlapsSpans returns whether the range from start to end overlaps any of the spans
+func overlapsSpans(spans [][]int, start, end int) bool {
+	for _, span := range spans {
+		if start < span[1] && end > span[0] {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultInlineTransforms are the inline transforms, that are applied, unless disabled, by their name
+var DefaultInlineTransforms = map[string]InlineTransform{
+	"highlight": HighlightTransform,
+}
+
+// inlineTransforms returns the enabled inline transforms, ordered by name
+func (c Converter) inlineTransforms() []InlineTransform {
+	transforms := make(map[string]InlineTransform)
+	for name, transform := range DefaultInlineTransforms {
+		transforms[name] = transform
+	}
+	for name, transform := range c.InlineTransforms {
+		transforms[name] = transform
+	}
+
+	names := make([]string, 0, len(transforms))
+	for name, transform := range transforms {
+		if transform != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	enabled := make([]InlineTransform, len(names))
+	for i, name := range names {
+		enabled[i] = transforms[name]
+	}
+	return enabled
+}
@@ -0,0 +1,59 @@
+package omh_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	omh "github.com/ukautz/obsidian-meets-hugo/pkg"
+)
+
+func TestHighlightTransform(t *testing.T) {
+	assert.Equal(t, "Some <mark>important</mark> and <mark>very important</mark> text",
+		omh.HighlightTransform("Some ==important== and ==very important== text"))
+	assert.Equal(t, "a === b\n==not\nspanning lines==", omh.HighlightTransform("a === b\n==not\nspanning lines=="))
+	assert.Equal(t, "if a == b and c == d", omh.HighlightTransform("if a == b and c == d"))
+	assert.Equal(t, "[q](https://x.com/?a==b==c) <https://x.com/?a==b==c> https://x.com/?a==b==c [[A==b==c]] <mark>x</mark>",
+		omh.HighlightTransform("[q](https://x.com/?a==b==c) <https://x.com/?a==b==c> https://x.com/?a==b==c [[A==b==c]] ==x=="))
+	assert.Equal(t, "[<mark>linked</mark>](https://x.com/)", omh.HighlightTransform("[==linked==](https://x.com/)"))
+	assert.Equal(t, "<mark>x</mark> and == spaced ==", omh.HighlightTransform("==x== and == spaced =="))
+}
+
+func TestConverter_Run_InlineTransforms(t *testing.T) {
+	notes := map[string]string{
+		"Index.md":           note("Some ==highlight== and `==code==`", "", "![[Embedded]]"),
+		"Arrows.md":          note("Some ==highlight== -> `a->b`", "", "![[Embedded Arrows]]"),
+		"Embedded Arrows.md": note("Embedded ==highlight== ->", "", "```", "a->b", "```"),
+		"Embedded.md": note(
+			"Embedded ==highlight==",
+			"",
+			"```",
+			"a ==b== c",
+			"```",
+		),
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		pages := convertVault(t, notes, nil)
+		assert.Equal(t, "Some <mark>highlight</mark> and `==code==`\n\n"+
+			"Embedded <mark>highlight</mark>\n\n```\na ==b== c\n```", pages["index.md"])
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		pages := convertVault(t, notes, func(converter *omh.Converter) {
+			converter.InlineTransforms = map[string]omh.InlineTransform{"highlight": nil}
+		})
+		assert.Equal(t, "Some ==highlight== and `==code==`\n\n"+
+			"Embedded ==highlight==\n\n```\na ==b== c\n```", pages["index.md"])
+	})
+
+	t.Run("additional", func(t *testing.T) {
+		pages := convertVault(t, notes, func(converter *omh.Converter) {
+			converter.InlineTransforms = map[string]omh.InlineTransform{"arrow": func(prose string) string {
+				return strings.ReplaceAll(prose, "->", "→")
+			}}
+		})
+		assert.Equal(t, "Some <mark>highlight</mark> → `a->b`\n\n"+
+			"Embedded <mark>highlight</mark> →\n\n```\na->b\n```", pages["arrows.md"])
+	})
+}